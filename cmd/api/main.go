@@ -7,6 +7,8 @@ import (
 	"converse/internal/db"
 	"converse/internal/handlers"
 	"converse/internal/middleware"
+	"converse/internal/services"
+	"converse/internal/storage"
 	"converse/internal/websocket"
 	"converse/migrations"
 
@@ -26,8 +28,14 @@ func main() {
         log.Fatalf("Failed to run migrations: %v", err)
     }
 
+	if err := storage.Init(cfg); err != nil {
+        log.Fatalf("Failed to initialize storage: %v", err)
+    }
+
 	hub := websocket.NewHub()
+    hub.SetActionHandler(services.NewMessageService(hub))
     go hub.Run()
+    go services.NewScheduledMessageService(hub).RunScheduler()
 
 	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
@@ -69,7 +77,16 @@ func setupRoutes(r *gin.Engine, hub *websocket.Hub) {
         authHandler := handlers.NewAuthHandler()
 		friendRequestHandler := handlers.NewFriendRequestHandler()
 		friendshipHandler := handlers.NewFriendshipHandler()
-		messageHandler := handlers.NewMessageHandler()
+		messageHandler := handlers.NewMessageHandler(hub)
+		attachmentHandler := handlers.NewAttachmentHandler(hub)
+		scheduledMessageHandler := handlers.NewScheduledMessageHandler(hub)
+		roomHandler := handlers.NewRoomHandler()
+		roomMemberHandler := handlers.NewRoomMemberHandler(hub)
+		inviteLinkHandler := handlers.NewRoomInviteLinkHandler(hub)
+		moderationHandler := handlers.NewRoomModerationHandler(hub)
+		roomRoleHandler := handlers.NewRoomRoleHandler()
+		spaceHandler := handlers.NewSpaceHandler()
+		inboxHandler := handlers.NewInboxHandler()
 		wsHandler := handlers.NewWebSocketHandler(hub)
 
 
@@ -119,14 +136,125 @@ func setupRoutes(r *gin.Engine, hub *websocket.Hub) {
 				friendships.GET("/", friendshipHandler.GetFriends)
 			}
 
+            // Room routes
+            rooms := protected.Group("/rooms")
+            {
+                rooms.POST("/", roomHandler.CreateRoom)
+                rooms.GET("/", roomHandler.GetUserRooms)
+                rooms.GET("/directory", roomHandler.GetDirectory)
+                rooms.GET("/:room_id", roomHandler.GetRoom)
+                rooms.PUT("/:room_id", roomHandler.UpdateRoom)
+                rooms.DELETE("/:room_id", roomHandler.DeleteRoom)
+                rooms.POST("/:room_id/transfer-ownership", roomHandler.TransferOwnership)
+                rooms.POST("/:room_id/archive", roomHandler.ArchiveRoom)
+                rooms.POST("/:room_id/unarchive", roomHandler.UnarchiveRoom)
+
+                // Membership
+                rooms.GET("/:room_id/members", roomMemberHandler.GetMembers)
+                rooms.POST("/:room_id/members", roomMemberHandler.AddMember)
+                rooms.DELETE("/:room_id/members/:user_id", roomMemberHandler.RemoveMember)
+                rooms.PUT("/:room_id/members/:user_id/promote", roomMemberHandler.PromoteMember)
+                rooms.PUT("/:room_id/members/:user_id/demote", roomMemberHandler.DemoteMember)
+                rooms.POST("/:room_id/join", roomMemberHandler.JoinRoom)
+                rooms.POST("/:room_id/leave", roomMemberHandler.LeaveRoom)
+                rooms.POST("/:room_id/invitations", roomMemberHandler.InviteUser)
+
+                // Custom roles and permissions
+                rooms.GET("/:room_id/roles", roomRoleHandler.GetRoles)
+                rooms.POST("/:room_id/roles", roomRoleHandler.CreateRole)
+                rooms.PUT("/:room_id/roles/:role_id", roomRoleHandler.UpdateRole)
+                rooms.DELETE("/:room_id/roles/:role_id", roomRoleHandler.DeleteRole)
+                rooms.PUT("/:room_id/members/:user_id/role", roomRoleHandler.AssignRole)
+                rooms.GET("/:room_id/permissions", roomRoleHandler.GetMyPermissions)
+
+                // Invite links
+                rooms.POST("/:room_id/invite-links", inviteLinkHandler.CreateInviteLink)
+                rooms.GET("/:room_id/invite-links", inviteLinkHandler.GetActiveInviteLinks)
+                rooms.DELETE("/:room_id/invite-links/:invite_link_id", inviteLinkHandler.RevokeInviteLink)
+
+                // Moderation
+                rooms.GET("/:room_id/bans", moderationHandler.GetBans)
+                rooms.POST("/:room_id/bans", moderationHandler.BanUser)
+                rooms.DELETE("/:room_id/bans/:user_id", moderationHandler.UnbanUser)
+                rooms.GET("/:room_id/mutes", moderationHandler.GetMutes)
+                rooms.POST("/:room_id/mutes", moderationHandler.MuteUser)
+                rooms.DELETE("/:room_id/mutes/:user_id", moderationHandler.UnmuteUser)
+            }
+
+            protected.POST("/invite-links/:code/redeem", inviteLinkHandler.RedeemInviteLink)
+
+            // Space routes; spaces are rooms, so membership goes through the room endpoints
+            spaces := protected.Group("/spaces")
+            {
+                spaces.POST("/", spaceHandler.CreateSpace)
+                spaces.GET("/:space_id/channels", spaceHandler.GetChannelTree)
+                spaces.POST("/:space_id/channels", spaceHandler.CreateChannel)
+                spaces.PUT("/:space_id/channels/:room_id", spaceHandler.MoveChannel)
+                spaces.POST("/:space_id/categories", spaceHandler.CreateCategory)
+                spaces.PUT("/:space_id/categories/:category_id", spaceHandler.UpdateCategory)
+                spaces.DELETE("/:space_id/categories/:category_id", spaceHandler.DeleteCategory)
+            }
+
+            // Every room and DM thread of the caller with its unread state
+            protected.GET("/inbox", inboxHandler.GetInbox)
+
+            roomInvitations := protected.Group("/room-invitations")
+            {
+                roomInvitations.GET("/", roomMemberHandler.GetPendingInvitations)
+                roomInvitations.PUT("/:invitation_id/accept", roomMemberHandler.AcceptInvitation)
+                roomInvitations.PUT("/:invitation_id/decline", roomMemberHandler.DeclineInvitation)
+            }
+
             // Message routes
             messages := protected.Group("/messages").Use(middleware.AuthMiddleware())
             {
                 // Room messages
                 messages.GET("/rooms/:room_id", messageHandler.GetMessagesByRoomID)
+                messages.GET("/rooms/:room_id/pins", messageHandler.GetRoomPins)
+                messages.GET("/rooms/:room_id/read-markers", messageHandler.GetRoomReadMarkers)
                 
                 // Thread messages
                 messages.GET("/threads/:thread_id", messageHandler.GetMessagesByThreadID)
+                messages.GET("/threads/:thread_id/pins", messageHandler.GetThreadPins)
+                messages.GET("/threads/:thread_id/read-markers", messageHandler.GetThreadReadMarkers)
+
+                // In-room threads started from a message
+                messages.GET("/:message_id/replies", messageHandler.GetThreadReplies)
+                messages.POST("/:message_id/follow", messageHandler.FollowThread)
+                messages.DELETE("/:message_id/follow", messageHandler.UnfollowThread)
+
+                // Full-text search
+                messages.GET("/search", messageHandler.SearchMessages)
+
+                // Messages that mentioned the caller
+                messages.GET("/mentions", messageHandler.GetMentions)
+
+                // Editing and deletion
+                messages.PUT("/:message_id", messageHandler.EditMessage)
+                messages.DELETE("/:message_id", messageHandler.DeleteMessage)
+                messages.GET("/:message_id/edits", messageHandler.GetEditHistory)
+
+                // Pins
+                messages.POST("/:message_id/pin", messageHandler.PinMessage)
+                messages.DELETE("/:message_id/pin", messageHandler.UnpinMessage)
+
+                // Read markers
+                messages.POST("/:message_id/read", messageHandler.MarkRead)
+
+                // Attachments are uploaded as multipart forms and sent as image_url or file_url messages
+                messages.POST("/attachments", attachmentHandler.UploadAttachment)
+                messages.GET("/:message_id/attachment", attachmentHandler.DownloadAttachment)
+                messages.GET("/:message_id/thumbnails/:size", attachmentHandler.DownloadThumbnail)
+
+                // Scheduled messages are sent by ScheduledMessageService.RunScheduler once due
+                messages.POST("/scheduled", scheduledMessageHandler.ScheduleMessage)
+                messages.GET("/scheduled", scheduledMessageHandler.GetScheduledMessages)
+                messages.PUT("/scheduled/:scheduled_message_id", scheduledMessageHandler.UpdateScheduledMessage)
+                messages.DELETE("/scheduled/:scheduled_message_id", scheduledMessageHandler.CancelScheduledMessage)
+
+                // Reactions; the emoji is URL-encoded in the path
+                messages.POST("/:message_id/reactions", messageHandler.AddReaction)
+                messages.DELETE("/:message_id/reactions/:emoji", messageHandler.RemoveReaction)
             }
         }
     }
@@ -4,18 +4,20 @@ go 1.23.0
 
 toolchain go1.23.5
 
-require github.com/gin-gonic/gin v1.10.1
+require (
+	github.com/gin-contrib/cors v1.7.5
+	github.com/gin-gonic/gin v1.10.1
+	github.com/google/uuid v1.6.0
+	github.com/gorilla/websocket v1.5.3
+	gorm.io/driver/mysql v1.5.7
+	gorm.io/gorm v1.26.1
+)
 
 require (
 	filippo.io/edwards25519 v1.1.0 // indirect
-	github.com/gin-contrib/cors v1.7.5 // indirect
 	github.com/go-sql-driver/mysql v1.9.2 // indirect
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
-	gorm.io/driver/mysql v1.5.7 // indirect
-	gorm.io/gorm v1.26.1 // indirect
 )
 
 require (
@@ -41,7 +43,7 @@ require (
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
 	github.com/ugorji/go/codec v1.2.12 // indirect
 	golang.org/x/arch v0.17.0 // indirect
-	golang.org/x/crypto v0.38.0 // indirect
+	golang.org/x/crypto v0.38.0
 	golang.org/x/net v0.40.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.25.0 // indirect
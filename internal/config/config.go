@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,6 +15,16 @@ type Config struct {
 	Environment     string
 	LogLevel        string
 	DatabaseURL     string
+
+	// Attachment storage; StorageBackend is "local" or "s3"
+	StorageBackend   string
+	StorageLocalPath string
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKeyID    string
+	S3SecretKey      string
+	S3UsePathStyle   bool
 }
 
 func New() *Config {
@@ -29,6 +40,15 @@ func New() *Config {
 		Environment:     getEnv("ENVIRONMENT", "development"),
 		LogLevel:        getEnv("LOG_LEVEL", "info"),
 		DatabaseURL:     getEnv("DATABASE_URL", ""),
+
+		StorageBackend:   getEnv("STORAGE_BACKEND", "local"),
+		StorageLocalPath: getEnv("STORAGE_LOCAL_PATH", "./uploads"),
+		S3Endpoint:       getEnv("S3_ENDPOINT", ""),
+		S3Region:         getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:         getEnv("S3_BUCKET", ""),
+		S3AccessKeyID:    getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretKey:      getEnv("S3_SECRET_ACCESS_KEY", ""),
+		S3UsePathStyle:   getBoolEnv("S3_USE_PATH_STYLE", true),
 	}
 }
 
@@ -51,6 +71,18 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Warning: invalid boolean for %s: %s", key, value)
+			return defaultValue
+		}
+		return parsed
+	}
+	return defaultValue
+}
+
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
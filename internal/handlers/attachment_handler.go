@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"converse/internal/services"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"converse/pkg/errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead leaves room for the form fields and boundaries around the uploaded file
+const multipartOverhead = 1 << 20
+
+// AttachmentHandler handles HTTP requests for uploading and downloading message attachments
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+}
+
+// NewAttachmentHandler creates a new attachment handler
+func NewAttachmentHandler(hub *websocket.Hub) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: services.NewAttachmentService(hub),
+	}
+}
+
+// UploadAttachment handles a multipart upload of a file sent as a new message
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxAttachmentSize+multipartOverhead)
+
+	var req types.UploadAttachmentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondWithError(c, errors.NewBadRequestError("Invalid upload", err.Error()))
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		respondWithError(c, errors.NewBadRequestError("A file is required", err.Error()))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+	defer file.Close()
+
+	message, err := h.attachmentService.UploadAttachment(userID, req, file, header)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// DownloadAttachment streams a message's uploaded file to a participant of its conversation
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	attachment, err := h.attachmentService.GetAttachment(c.Param("message_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	serveAttachment(c, attachment)
+}
+
+// DownloadThumbnail streams one of the thumbnails of an uploaded image
+func (h *AttachmentHandler) DownloadThumbnail(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	size, err := strconv.Atoi(c.Param("size"))
+	if err != nil {
+		respondWithError(c, errors.NewNotFoundError("Thumbnail not found"))
+		return
+	}
+
+	attachment, err := h.attachmentService.GetThumbnail(c.Param("message_id"), userID, size)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	serveAttachment(c, attachment)
+}
+
+// serveAttachment streams a stored file. Images are shown inline; anything else is downloaded
+// rather than rendered by the browser.
+func serveAttachment(c *gin.Context, attachment *services.Attachment) {
+	defer attachment.Content.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.MimeType, "image/") {
+		disposition = "inline"
+	}
+
+	headers := map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=86400",
+	}
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.MimeType, attachment.Content, headers)
+}
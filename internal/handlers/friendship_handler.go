@@ -37,7 +37,7 @@ func (h *FriendshipHandler) GetFriends(c *gin.Context) {
 		return
 	}
 
-	friends, err := h.friendshipService.GetFriends(userIDStr)
+	friends, err := h.friendshipService.GetFriends(userIDStr, c.Query("sort"))
 	if err != nil {
 		switch appErr := err.(type) {
 		case *errors.AppError:
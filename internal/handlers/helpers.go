@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"converse/internal/types"
+	"converse/pkg/errors"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getUserID reads the authenticated user's ID from the request context,
+// writing an error response and returning false when it is missing
+func getUserID(c *gin.Context) (string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, &errors.AppError{
+			Code:    http.StatusUnauthorized,
+			Message: "User ID not found in token",
+		})
+		return "", false
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, &errors.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: "Invalid user ID format",
+		})
+		return "", false
+	}
+
+	return userIDStr, true
+}
+
+// respondWithError writes an AppError as-is and hides any other error behind a 500
+func respondWithError(c *gin.Context, err error) {
+	switch appErr := err.(type) {
+	case *errors.AppError:
+		c.JSON(appErr.Code, appErr)
+	default:
+		c.JSON(http.StatusInternalServerError, &errors.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+	}
+}
+
+// bindJSON binds the request body, writing a 400 response on failure
+func bindJSON(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, &errors.AppError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// bindOptionalJSON is bindJSON for requests whose fields are all optional, so an empty body is
+// accepted and leaves req at its zero value
+func bindOptionalJSON(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil && !stderrors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, &errors.AppError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request body",
+			Details: err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// getPaginationParams extracts and validates pagination parameters from the request
+func getPaginationParams(c *gin.Context) (int, int) {
+	// Default values
+	defaultPage := 1
+	defaultPageSize := 20
+	maxPageSize := 100
+
+	// Get page parameter
+	pageStr := c.DefaultQuery("page", strconv.Itoa(defaultPage))
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = defaultPage
+	}
+
+	// Get page_size parameter
+	pageSizeStr := c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize))
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	// Limit the maximum page size
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	return page, pageSize
+}
+
+// getMessagePageQuery extracts the history cursors and a bounded limit from the request
+func getMessagePageQuery(c *gin.Context) types.MessagePageQuery {
+	defaultLimit := 50
+	maxLimit := 100
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	return types.MessagePageQuery{
+		Before: c.Query("before"),
+		After:  c.Query("after"),
+		Around: c.Query("around"),
+		Limit:  limit,
+	}
+}
+
+// getOlderPageParams extracts the before cursor and a bounded limit for lists that only page
+// towards older entries. It responds with an error and returns false if after or around is given.
+func getOlderPageParams(c *gin.Context) (string, int, bool) {
+	query := getMessagePageQuery(c)
+	if query.After != "" || query.Around != "" {
+		respondWithError(c, errors.NewBadRequestError("Invalid cursor", "This list only pages towards older entries with before"))
+		return "", 0, false
+	}
+	return query.Before, query.Limit, true
+}
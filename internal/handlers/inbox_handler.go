@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"converse/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InboxHandler handles HTTP requests for the user's conversation list
+type InboxHandler struct {
+	inboxService *services.InboxService
+}
+
+// NewInboxHandler creates a new inbox handler
+func NewInboxHandler() *InboxHandler {
+	return &InboxHandler{
+		inboxService: services.NewInboxService(),
+	}
+}
+
+// GetInbox handles the request to list the user's rooms and DM threads with their unread state
+func (h *InboxHandler) GetInbox(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.inboxService.GetInbox(userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
 	"converse/internal/services"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"converse/pkg/errors"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,13 +17,13 @@ type MessageHandler struct {
 }
 
 // NewMessageHandler creates a new message handler
-func NewMessageHandler() *MessageHandler {
+func NewMessageHandler(hub *websocket.Hub) *MessageHandler {
 	return &MessageHandler{
-		messageService: services.NewMessageService(),
+		messageService: services.NewMessageService(hub),
 	}
 }
 
-// GetMessagesByRoomID handles the request to get paginated messages for a room
+// GetMessagesByRoomID handles the request to get a page of a room's message history
 func (h *MessageHandler) GetMessagesByRoomID(c *gin.Context) {
 	roomID := c.Param("room_id")
 	if roomID == "" {
@@ -29,20 +31,29 @@ func (h *MessageHandler) GetMessagesByRoomID(c *gin.Context) {
 		return
 	}
 
-	// Parse pagination parameters with defaults
-	page, pageSize := h.getPaginationParams(c)
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	// Parse cursor parameters with defaults
+	query := getMessagePageQuery(c)
 
 	// Get messages from service
-	paginatedMessages, err := h.messageService.GetMessagesByRoomID(roomID, page, pageSize)
+	messagePage, err := h.messageService.GetMessagesByRoomID(roomID, userID, query)
 	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, appErr)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
 		return
 	}
 
-	c.JSON(http.StatusOK, paginatedMessages)
+	c.JSON(http.StatusOK, messagePage)
 }
 
-// GetMessagesByThreadID handles the request to get paginated messages for a thread
+// GetMessagesByThreadID handles the request to get a page of a thread's message history
 func (h *MessageHandler) GetMessagesByThreadID(c *gin.Context) {
 	threadID := c.Param("thread_id")
 	if threadID == "" {
@@ -50,44 +61,322 @@ func (h *MessageHandler) GetMessagesByThreadID(c *gin.Context) {
 		return
 	}
 
-	// Parse pagination parameters with defaults
-	page, pageSize := h.getPaginationParams(c)
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	// Parse cursor parameters with defaults
+	query := getMessagePageQuery(c)
 
 	// Get messages from service
-	paginatedMessages, err := h.messageService.GetMessagesByThreadID(threadID, page, pageSize)
+	messagePage, err := h.messageService.GetMessagesByThreadID(threadID, userID, query)
 	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, appErr)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
 		return
 	}
 
-	c.JSON(http.StatusOK, paginatedMessages)
+	c.JSON(http.StatusOK, messagePage)
 }
 
-// getPaginationParams extracts and validates pagination parameters from the request
-func (h *MessageHandler) getPaginationParams(c *gin.Context) (int, int) {
-	// Default values
-	defaultPage := 1
-	defaultPageSize := 20
-	maxPageSize := 100
+// GetThreadReplies handles the request to get a page of replies in a room message's thread
+func (h *MessageHandler) GetThreadReplies(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
 
-	// Get page parameter
-	pageStr := c.DefaultQuery("page", strconv.Itoa(defaultPage))
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = defaultPage
+	messagePage, err := h.messageService.GetThreadReplies(c.Param("message_id"), userID, getMessagePageQuery(c))
+	if err != nil {
+		respondWithError(c, err)
+		return
 	}
 
-	// Get page_size parameter
-	pageSizeStr := c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize))
-	pageSize, err := strconv.Atoi(pageSizeStr)
-	if err != nil || pageSize < 1 {
-		pageSize = defaultPageSize
+	c.JSON(http.StatusOK, messagePage)
+}
+
+// FollowThread handles the request to follow a room message's thread
+func (h *MessageHandler) FollowThread(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.FollowThread(c.Param("message_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Thread followed successfully"})
+}
+
+// UnfollowThread handles the request to stop following a room message's thread
+func (h *MessageHandler) UnfollowThread(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
 	}
 
-	// Limit the maximum page size
-	if pageSize > maxPageSize {
-		pageSize = maxPageSize
+	if err := h.messageService.UnfollowThread(c.Param("message_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Thread unfollowed successfully"})
+}
+
+// SearchMessages handles the request to search the user's rooms and DM threads
+func (h *MessageHandler) SearchMessages(c *gin.Context) {
+	var query types.SearchMessagesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, &errors.AppError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid search parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	before, limit, ok := getOlderPageParams(c)
+	if !ok {
+		return
+	}
+
+	results, err := h.messageService.SearchMessages(userID, query, before, limit)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetMentions handles the request to list the messages that mentioned the user
+func (h *MessageHandler) GetMentions(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	before, limit, ok := getOlderPageParams(c)
+	if !ok {
+		return
+	}
+
+	mentions, err := h.messageService.GetMentions(userID, before, limit)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mentions)
+}
+
+// EditMessage handles the request to edit one of the user's messages
+func (h *MessageHandler) EditMessage(c *gin.Context) {
+	var req types.EditMessageRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	message, err := h.messageService.EditMessage(c.Param("message_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// DeleteMessage handles the request to delete a message for everyone
+func (h *MessageHandler) DeleteMessage(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.DeleteMessage(c.Param("message_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted successfully"})
+}
+
+// PinMessage handles the request to pin a message
+func (h *MessageHandler) PinMessage(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	message, err := h.messageService.PinMessage(c.Param("message_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// UnpinMessage handles the request to unpin a message
+func (h *MessageHandler) UnpinMessage(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	message, err := h.messageService.UnpinMessage(c.Param("message_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// MarkRead handles the request to mark a message and everything before it as read
+func (h *MessageHandler) MarkRead(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.MarkRead(c.Param("message_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message marked as read"})
+}
+
+// GetRoomReadMarkers handles the request to list how far a room's members have read
+func (h *MessageHandler) GetRoomReadMarkers(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	markers, err := h.messageService.GetRoomReadMarkers(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, markers)
+}
+
+// GetThreadReadMarkers handles the request to list how far a DM thread's participants have read
+func (h *MessageHandler) GetThreadReadMarkers(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	markers, err := h.messageService.GetThreadReadMarkers(c.Param("thread_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, markers)
+}
+
+// GetRoomPins handles the request to list a room's pinned messages
+func (h *MessageHandler) GetRoomPins(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	pins, err := h.messageService.GetRoomPins(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pins)
+}
+
+// GetThreadPins handles the request to list a DM thread's pinned messages
+func (h *MessageHandler) GetThreadPins(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	pins, err := h.messageService.GetThreadPins(c.Param("thread_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pins)
+}
+
+// AddReaction handles the request to react to a message with an emoji
+func (h *MessageHandler) AddReaction(c *gin.Context) {
+	var req types.ReactionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.messageService.AddReaction(c.Param("message_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reactions)
+}
+
+// RemoveReaction handles the request to withdraw an emoji reaction
+func (h *MessageHandler) RemoveReaction(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.messageService.RemoveReaction(c.Param("message_id"), userID, c.Param("emoji"))
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reactions)
+}
+
+// GetEditHistory handles the request to list the prior versions of a message
+func (h *MessageHandler) GetEditHistory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	edits, err := h.messageService.GetEditHistory(c.Param("message_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
 	}
 
-	return page, pageSize
+	c.JSON(http.StatusOK, edits)
 }
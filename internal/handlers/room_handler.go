@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"converse/internal/services"
+	"converse/internal/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomHandler handles HTTP requests related to rooms
+type RoomHandler struct {
+	roomService *services.RoomService
+}
+
+// NewRoomHandler creates a new room handler
+func NewRoomHandler() *RoomHandler {
+	return &RoomHandler{
+		roomService: services.NewRoomService(),
+	}
+}
+
+func (h *RoomHandler) CreateRoom(c *gin.Context) {
+	var req types.CreateRoomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	room, err := h.roomService.CreateRoom(req, userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, room)
+}
+
+func (h *RoomHandler) GetRoom(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	room, err := h.roomService.GetRoom(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, room)
+}
+
+func (h *RoomHandler) GetUserRooms(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	rooms, err := h.roomService.GetUserRooms(userID, c.Query("sort"))
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rooms)
+}
+
+func (h *RoomHandler) GetDirectory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := getPaginationParams(c)
+
+	rooms, err := h.roomService.GetDirectory(userID, c.Query("q"), c.Query("sort"), page, pageSize)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rooms)
+}
+
+func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+	var req types.UpdateRoomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	room, err := h.roomService.UpdateRoom(c.Param("room_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, room)
+}
+
+func (h *RoomHandler) TransferOwnership(c *gin.Context) {
+	var req types.TransferOwnershipRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roomService.TransferOwnership(c.Param("room_id"), userID, req); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transferred successfully"})
+}
+
+func (h *RoomHandler) ArchiveRoom(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	room, err := h.roomService.ArchiveRoom(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, room)
+}
+
+func (h *RoomHandler) UnarchiveRoom(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	room, err := h.roomService.UnarchiveRoom(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, room)
+}
+
+func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roomService.DeleteRoom(c.Param("room_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Room deleted successfully"})
+}
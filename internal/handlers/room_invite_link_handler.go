@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"converse/internal/services"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomInviteLinkHandler handles HTTP requests related to room invite links
+type RoomInviteLinkHandler struct {
+	inviteLinkService *services.RoomInviteLinkService
+}
+
+// NewRoomInviteLinkHandler creates a new room invite link handler
+func NewRoomInviteLinkHandler(hub *websocket.Hub) *RoomInviteLinkHandler {
+	return &RoomInviteLinkHandler{
+		inviteLinkService: services.NewRoomInviteLinkService(hub),
+	}
+}
+
+func (h *RoomInviteLinkHandler) CreateInviteLink(c *gin.Context) {
+	var req types.CreateInviteLinkRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	link, err := h.inviteLinkService.CreateInviteLink(c.Param("room_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+func (h *RoomInviteLinkHandler) GetActiveInviteLinks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	links, err := h.inviteLinkService.GetActiveInviteLinks(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+func (h *RoomInviteLinkHandler) RevokeInviteLink(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.inviteLinkService.RevokeInviteLink(c.Param("room_id"), c.Param("invite_link_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite link revoked successfully"})
+}
+
+func (h *RoomInviteLinkHandler) RedeemInviteLink(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	room, err := h.inviteLinkService.RedeemInviteLink(c.Param("code"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, room)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"converse/internal/services"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomMemberHandler handles HTTP requests related to room membership
+type RoomMemberHandler struct {
+	roomMemberService *services.RoomMemberService
+}
+
+// NewRoomMemberHandler creates a new room member handler
+func NewRoomMemberHandler(hub *websocket.Hub) *RoomMemberHandler {
+	return &RoomMemberHandler{
+		roomMemberService: services.NewRoomMemberService(hub),
+	}
+}
+
+func (h *RoomMemberHandler) GetMembers(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.roomMemberService.GetMembers(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+func (h *RoomMemberHandler) AddMember(c *gin.Context) {
+	var req types.AddRoomMemberRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roomMemberService.AddMember(c.Param("room_id"), userID, req.Username); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Member added successfully"})
+}
+
+func (h *RoomMemberHandler) RemoveMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roomMemberService.RemoveMember(c.Param("room_id"), userID, c.Param("user_id")); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+func (h *RoomMemberHandler) PromoteMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.roomMemberService.PromoteMember(c.Param("room_id"), userID, c.Param("user_id"))
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *RoomMemberHandler) DemoteMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.roomMemberService.DemoteMember(c.Param("room_id"), userID, c.Param("user_id"))
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *RoomMemberHandler) JoinRoom(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roomMemberService.JoinRoom(c.Param("room_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Joined room successfully"})
+}
+
+func (h *RoomMemberHandler) LeaveRoom(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roomMemberService.LeaveRoom(c.Param("room_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left room successfully"})
+}
+
+func (h *RoomMemberHandler) InviteUser(c *gin.Context) {
+	var req types.InviteToRoomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.roomMemberService.InviteUser(c.Param("room_id"), userID, req.Username)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+func (h *RoomMemberHandler) GetPendingInvitations(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.roomMemberService.GetPendingInvitations(userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+func (h *RoomMemberHandler) AcceptInvitation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.roomMemberService.AcceptInvitation(c.Param("invitation_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
+
+func (h *RoomMemberHandler) DeclineInvitation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roomMemberService.DeclineInvitation(c.Param("invitation_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined successfully"})
+}
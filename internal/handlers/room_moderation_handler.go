@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"converse/internal/services"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomModerationHandler handles HTTP requests related to room bans and mutes
+type RoomModerationHandler struct {
+	moderationService *services.RoomModerationService
+}
+
+// NewRoomModerationHandler creates a new room moderation handler
+func NewRoomModerationHandler(hub *websocket.Hub) *RoomModerationHandler {
+	return &RoomModerationHandler{
+		moderationService: services.NewRoomModerationService(hub),
+	}
+}
+
+func (h *RoomModerationHandler) BanUser(c *gin.Context) {
+	var req types.BanUserRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	ban, err := h.moderationService.BanUser(c.Param("room_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ban)
+}
+
+func (h *RoomModerationHandler) UnbanUser(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.moderationService.UnbanUser(c.Param("room_id"), userID, c.Param("user_id")); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unbanned successfully"})
+}
+
+func (h *RoomModerationHandler) GetBans(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	bans, err := h.moderationService.GetBans(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bans)
+}
+
+func (h *RoomModerationHandler) MuteUser(c *gin.Context) {
+	var req types.MuteUserRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	mute, err := h.moderationService.MuteUser(c.Param("room_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mute)
+}
+
+func (h *RoomModerationHandler) UnmuteUser(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.moderationService.UnmuteUser(c.Param("room_id"), userID, c.Param("user_id")); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unmuted successfully"})
+}
+
+func (h *RoomModerationHandler) GetMutes(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	mutes, err := h.moderationService.GetMutes(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mutes)
+}
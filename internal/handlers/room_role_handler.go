@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"converse/internal/services"
+	"converse/internal/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomRoleHandler handles HTTP requests related to custom room roles
+type RoomRoleHandler struct {
+	roleService *services.RoomRoleService
+}
+
+// NewRoomRoleHandler creates a new room role handler
+func NewRoomRoleHandler() *RoomRoleHandler {
+	return &RoomRoleHandler{
+		roleService: services.NewRoomRoleService(),
+	}
+}
+
+func (h *RoomRoleHandler) GetRoles(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	roles, err := h.roleService.GetRoles(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+func (h *RoomRoleHandler) GetMyPermissions(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	permissions, err := h.roleService.GetMyPermissions(c.Param("room_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"permissions": permissions})
+}
+
+func (h *RoomRoleHandler) CreateRole(c *gin.Context) {
+	var req types.CreateRoomRoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c.Param("room_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+func (h *RoomRoleHandler) UpdateRole(c *gin.Context) {
+	var req types.UpdateRoomRoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(c.Param("room_id"), c.Param("role_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (h *RoomRoleHandler) DeleteRole(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.DeleteRole(c.Param("room_id"), c.Param("role_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+func (h *RoomRoleHandler) AssignRole(c *gin.Context) {
+	var req types.AssignRoomRoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.AssignRole(c.Param("room_id"), userID, c.Param("user_id"), req); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role assigned successfully"})
+}
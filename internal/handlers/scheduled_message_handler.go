@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"converse/internal/services"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduledMessageHandler handles HTTP requests for messages scheduled to be sent later
+type ScheduledMessageHandler struct {
+	scheduledMessageService *services.ScheduledMessageService
+}
+
+// NewScheduledMessageHandler creates a new scheduled message handler
+func NewScheduledMessageHandler(hub *websocket.Hub) *ScheduledMessageHandler {
+	return &ScheduledMessageHandler{
+		scheduledMessageService: services.NewScheduledMessageService(hub),
+	}
+}
+
+// ScheduleMessage handles the request to schedule a message to a room or DM thread
+func (h *ScheduledMessageHandler) ScheduleMessage(c *gin.Context) {
+	var req types.ScheduleMessageRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduled, err := h.scheduledMessageService.ScheduleMessage(userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, scheduled)
+}
+
+// GetScheduledMessages handles the request to list the user's unsent scheduled messages
+func (h *ScheduledMessageHandler) GetScheduledMessages(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduled, err := h.scheduledMessageService.GetScheduledMessages(userID, c.Query("room_id"), c.Query("thread_id"))
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// UpdateScheduledMessage handles the request to edit or reschedule a scheduled message
+func (h *ScheduledMessageHandler) UpdateScheduledMessage(c *gin.Context) {
+	var req types.UpdateScheduledMessageRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduled, err := h.scheduledMessageService.UpdateScheduledMessage(c.Param("scheduled_message_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// CancelScheduledMessage handles the request to cancel a scheduled message before it is sent
+func (h *ScheduledMessageHandler) CancelScheduledMessage(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.scheduledMessageService.CancelScheduledMessage(c.Param("scheduled_message_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scheduled message cancelled"})
+}
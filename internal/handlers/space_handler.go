@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"converse/internal/services"
+	"converse/internal/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SpaceHandler handles HTTP requests related to spaces and their channel tree
+type SpaceHandler struct {
+	spaceService *services.SpaceService
+}
+
+// NewSpaceHandler creates a new space handler
+func NewSpaceHandler() *SpaceHandler {
+	return &SpaceHandler{
+		spaceService: services.NewSpaceService(),
+	}
+}
+
+func (h *SpaceHandler) CreateSpace(c *gin.Context) {
+	var req types.CreateRoomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	space, err := h.spaceService.CreateSpace(req, userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, space)
+}
+
+func (h *SpaceHandler) GetChannelTree(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	tree, err := h.spaceService.GetChannelTree(c.Param("space_id"), userID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+func (h *SpaceHandler) CreateChannel(c *gin.Context) {
+	var req types.CreateChannelRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	channel, err := h.spaceService.CreateChannel(c.Param("space_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, channel)
+}
+
+func (h *SpaceHandler) MoveChannel(c *gin.Context) {
+	var req types.MoveChannelRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	channel, err := h.spaceService.MoveChannel(c.Param("space_id"), c.Param("room_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+func (h *SpaceHandler) CreateCategory(c *gin.Context) {
+	var req types.CreateCategoryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	category, err := h.spaceService.CreateCategory(c.Param("space_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *SpaceHandler) UpdateCategory(c *gin.Context) {
+	var req types.UpdateCategoryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	category, err := h.spaceService.UpdateCategory(c.Param("space_id"), c.Param("category_id"), userID, req)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *SpaceHandler) DeleteCategory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.spaceService.DeleteCategory(c.Param("space_id"), c.Param("category_id"), userID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
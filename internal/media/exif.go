@@ -0,0 +1,271 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// EXIF orientations; 1 is upright and 5-8 swap width and height
+const (
+	orientationNormal    = 1
+	orientationTranspose = 5
+)
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	mpfHeader  = []byte("MPF\x00")
+	pngMagic   = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// ErrMalformedImage is returned for images whose structure cannot be parsed well enough to be
+// sure their metadata was removed. Such images are rejected rather than stored as uploaded.
+var ErrMalformedImage = errors.New("malformed image")
+
+// stripJPEGMetadata removes the EXIF and XMP segments of a JPEG, along with IPTC data and comments,
+// which drops GPS location, device details and timestamps without re-encoding the image. Anything
+// after the end of the image, such as the extra pictures some phones append, is dropped too. The
+// orientation is kept by writing back a minimal EXIF segment holding only that tag. Returns the
+// new file and the orientation.
+func stripJPEGMetadata(data []byte) ([]byte, int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, ErrMalformedImage
+	}
+
+	orientation := orientationNormal
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+
+	pos := 2
+	for {
+		// Any number of 0xFF fill bytes may come before a marker
+		for pos+1 < len(data) && data[pos] == 0xFF && data[pos+1] == 0xFF {
+			pos++
+		}
+		if pos+2 > len(data) || data[pos] != 0xFF {
+			return nil, 0, ErrMalformedImage
+		}
+		marker := data[pos+1]
+
+		switch {
+		case marker == 0xD9:
+			// End of image
+			out = append(out, 0xFF, 0xD9)
+			return withOrientation(out, orientation), orientation, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// Markers without a length
+			out = append(out, data[pos:pos+2]...)
+			pos += 2
+			continue
+		case marker == 0x00 || marker == 0xD8:
+			return nil, 0, ErrMalformedImage
+		}
+
+		if pos+4 > len(data) {
+			return nil, 0, ErrMalformedImage
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, 0, ErrMalformedImage
+		}
+		segment := data[pos:end]
+
+		switch {
+		case marker == 0xE1:
+			// APP1 holds both EXIF and XMP; only the orientation is carried over
+			if bytes.HasPrefix(segment[4:], exifHeader) {
+				if o := readOrientation(segment[4+len(exifHeader):]); o != 0 {
+					orientation = o
+				}
+			}
+		case marker == 0xED || marker == 0xFE:
+			// APP13 holds Photoshop and IPTC data, COM free-form comments
+		case marker == 0xE2 && bytes.HasPrefix(segment[4:], mpfHeader):
+			// Describes the appended pictures, which are dropped
+		default:
+			out = append(out, segment...)
+		}
+		pos = end
+
+		if marker == 0xDA {
+			// Start of scan: copy the entropy-coded data up to the next marker. Inside it 0xFF is
+			// followed by a stuffed zero or a restart marker.
+			scanEnd := pos
+			for scanEnd+1 < len(data) {
+				if data[scanEnd] == 0xFF && data[scanEnd+1] != 0x00 && (data[scanEnd+1] < 0xD0 || data[scanEnd+1] > 0xD7) {
+					break
+				}
+				scanEnd++
+			}
+			if scanEnd+1 >= len(data) {
+				return nil, 0, ErrMalformedImage
+			}
+			out = append(out, data[pos:scanEnd]...)
+			pos = scanEnd
+		}
+	}
+}
+
+// withOrientation inserts an EXIF segment holding only the orientation into a stripped JPEG,
+// unless the image is stored upright
+func withOrientation(jpeg []byte, orientation int) []byte {
+	if orientation == orientationNormal {
+		return jpeg
+	}
+
+	// Put the orientation segment after SOI, or after the JFIF APP0 segment that must come first
+	insertAt := 2
+	if len(jpeg) >= 6 && jpeg[2] == 0xFF && jpeg[3] == 0xE0 {
+		insertAt = 4 + int(binary.BigEndian.Uint16(jpeg[4:]))
+	}
+
+	exif := orientationSegment(orientation)
+	out := make([]byte, 0, len(jpeg)+len(exif))
+	out = append(out, jpeg[:insertAt]...)
+	out = append(out, exif...)
+	return append(out, jpeg[insertAt:]...)
+}
+
+// readOrientation finds the orientation tag in IFD0 of a TIFF-structured EXIF block, or returns 0
+func readOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 0
+			}
+			return orientation
+		}
+	}
+	return 0
+}
+
+// orientationSegment builds an APP1 EXIF segment whose IFD0 holds just the orientation tag
+func orientationSegment(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // big-endian header, IFD0 at offset 8
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, // orientation, SHORT, count 1
+		0x00, byte(orientation), 0x00, 0x00, // value, padded
+		0x00, 0x00, 0x00, 0x00, // no next IFD
+	}
+
+	payload := append(append([]byte{}, exifHeader...), tiff...)
+	segment := []byte{0xFF, 0xE1, 0x00, 0x00}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// pngMetadataChunks are the PNG chunks dropped from uploads: EXIF, the text chunks that carry XMP
+// and free-form comments, and the modification time
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// stripPNGMetadata removes the chunks where PNGs keep EXIF, XMP and other metadata, including
+// GPS location, and anything after the end of the image
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngMagic) {
+		return nil, ErrMalformedImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngMagic...)
+
+	pos := len(pngMagic)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		chunkType := string(data[pos+4 : pos+8])
+		if !pngMetadataChunks[chunkType] {
+			out = append(out, data[pos:end]...)
+		}
+		if chunkType == "IEND" {
+			return out, nil
+		}
+		pos = end
+	}
+
+	return nil, ErrMalformedImage
+}
+
+// webPMetadataFlags are the VP8X header flags announcing EXIF and XMP chunks
+const webPMetadataFlags = 0x08 | 0x04
+
+// stripWebPMetadata removes the EXIF and XMP chunks of an extended WebP and clears the flags
+// announcing them
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrMalformedImage
+	}
+
+	riffEnd := 8 + int(binary.LittleEndian.Uint32(data[4:]))
+	if riffEnd > len(data) || riffEnd < 12 {
+		return nil, ErrMalformedImage
+	}
+	data = data[:riffEnd]
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, ErrMalformedImage
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		switch string(data[pos : pos+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte{}, data[pos:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= webPMetadataFlags
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
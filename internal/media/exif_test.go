@@ -0,0 +1,172 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The fixtures carry GPS coordinates in an EXIF GPS IFD and in an XMP packet. The JPEG also has a
+// comment with the location, fill bytes before a marker and a second picture appended after its
+// end; it is stored sideways with orientation 6.
+var (
+	gpsRationals  = []byte{0x00, 0x00, 0x00, 0x34, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x16} // latitude 52/1, 22/...
+	xmpNamespace  = []byte("http://ns.adobe.com/xap/1.0/")
+	xmpGPS        = []byte("exif:GPSLatitude")
+	fixtureCamera = []byte("GPS Fixture Camera")
+	locationNote  = []byte("Taken at 52.3701N")
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// assertNoLocation fails if any of the fixture's identifying metadata survived
+func assertNoLocation(t *testing.T, data []byte) {
+	t.Helper()
+	for _, needle := range [][]byte{gpsRationals, xmpNamespace, xmpGPS, fixtureCamera, locationNote} {
+		if bytes.Contains(data, needle) {
+			t.Errorf("stripped image still contains %q", needle)
+		}
+	}
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	data := readFixture(t, "gps.jpg")
+	if !bytes.Contains(data, gpsRationals) || !bytes.Contains(data, xmpGPS) {
+		t.Fatal("fixture is missing its GPS metadata")
+	}
+
+	stripped, orientation, err := stripJPEGMetadata(data)
+	if err != nil {
+		t.Fatalf("stripJPEGMetadata: %v", err)
+	}
+	if orientation != 6 {
+		t.Errorf("orientation = %d, want 6", orientation)
+	}
+	assertNoLocation(t, stripped)
+
+	if !bytes.HasSuffix(stripped, []byte{0xFF, 0xD9}) || bytes.Count(stripped, []byte{0xFF, 0xD8}) != 1 {
+		t.Error("the appended picture was not dropped")
+	}
+
+	// The JFIF segment stays first, followed by an EXIF segment holding only the orientation
+	jfifEnd := 4 + int(binary.BigEndian.Uint16(stripped[4:]))
+	if stripped[3] != 0xE0 || !bytes.HasPrefix(stripped[jfifEnd:], orientationSegment(6)) {
+		t.Error("orientation segment is missing or misplaced")
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("stripped JPEG does not decode: %v", err)
+	}
+	if img.Bounds().Dx() != 64 || img.Bounds().Dy() != 48 {
+		t.Errorf("decoded size = %v, want 64x48", img.Bounds().Size())
+	}
+}
+
+func TestStripJPEGMetadataMalformed(t *testing.T) {
+	data := readFixture(t, "gps.jpg")
+	sos := bytes.Index(data, []byte{0xFF, 0xDA})
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not a JPEG", []byte("GIF89a......")},
+		{"truncated segment", data[:30]},
+		{"truncated scan", data[:sos+40]},
+		{"zero length segment", append([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x00}, data[2:]...)},
+		{"garbage between segments", append([]byte{0xFF, 0xD8, 0x12, 0x34}, data[2:]...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := stripJPEGMetadata(tt.data); !errors.Is(err, ErrMalformedImage) {
+				t.Errorf("err = %v, want ErrMalformedImage", err)
+			}
+		})
+	}
+}
+
+func TestStripPNGMetadata(t *testing.T) {
+	data := readFixture(t, "gps.png")
+	if !bytes.Contains(data, []byte("eXIf")) || !bytes.Contains(data, []byte("XML:com.adobe.xmp")) {
+		t.Fatal("fixture is missing its GPS metadata")
+	}
+
+	stripped, err := stripPNGMetadata(append(data, "trailing data"...))
+	if err != nil {
+		t.Fatalf("stripPNGMetadata: %v", err)
+	}
+	assertNoLocation(t, stripped)
+	for _, chunkType := range []string{"eXIf", "iTXt", "tEXt"} {
+		if bytes.Contains(stripped, []byte(chunkType)) {
+			t.Errorf("%s chunk was not removed", chunkType)
+		}
+	}
+	if !bytes.HasSuffix(stripped, []byte("IEND\xaeB`\x82")) {
+		t.Error("data after IEND was not dropped")
+	}
+
+	img, err := png.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("stripped PNG does not decode: %v", err)
+	}
+	if img.Bounds().Dx() != 64 || img.Bounds().Dy() != 48 {
+		t.Errorf("decoded size = %v, want 64x48", img.Bounds().Size())
+	}
+
+	for _, malformed := range [][]byte{nil, data[:40], data[:len(data)-12]} {
+		if _, err := stripPNGMetadata(malformed); !errors.Is(err, ErrMalformedImage) {
+			t.Errorf("stripPNGMetadata(%d bytes) err = %v, want ErrMalformedImage", len(malformed), err)
+		}
+	}
+}
+
+func TestStripWebPMetadata(t *testing.T) {
+	data := readFixture(t, "gps.webp")
+	if !bytes.Contains(data, []byte("EXIF")) || !bytes.Contains(data, []byte("XMP ")) {
+		t.Fatal("fixture is missing its GPS metadata")
+	}
+
+	stripped, err := stripWebPMetadata(data)
+	if err != nil {
+		t.Fatalf("stripWebPMetadata: %v", err)
+	}
+	assertNoLocation(t, stripped)
+
+	// Walk the result to check the chunks that are left and the header flags
+	if size := int(binary.LittleEndian.Uint32(stripped[4:])); size != len(stripped)-8 {
+		t.Errorf("RIFF size = %d, want %d", size, len(stripped)-8)
+	}
+	var chunks []string
+	for pos := 12; pos < len(stripped); {
+		size := int(binary.LittleEndian.Uint32(stripped[pos+4:]))
+		chunks = append(chunks, string(stripped[pos:pos+4]))
+		if string(stripped[pos:pos+4]) == "VP8X" && stripped[pos+8]&webPMetadataFlags != 0 {
+			t.Error("VP8X still announces EXIF or XMP")
+		}
+		pos += 8 + size + size%2
+	}
+	if len(chunks) != 2 || chunks[0] != "VP8X" || chunks[1] != "VP8L" {
+		t.Errorf("chunks = %q, want VP8X and VP8L", chunks)
+	}
+
+	for _, malformed := range [][]byte{nil, data[:20], data[:len(data)-4]} {
+		if _, err := stripWebPMetadata(malformed); !errors.Is(err, ErrMalformedImage) {
+			t.Errorf("stripWebPMetadata(%d bytes) err = %v, want ErrMalformedImage", len(malformed), err)
+		}
+	}
+}
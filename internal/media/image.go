@@ -0,0 +1,145 @@
+// Package media prepares uploaded images: it strips location and other EXIF metadata,
+// measures them and renders thumbnails and a blurhash placeholder using only the standard library.
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"sort"
+
+	// Register the GIF decoder for image.Decode
+	_ "image/gif"
+)
+
+// ThumbnailSizes are the bounding boxes, in pixels, of the thumbnails rendered for an image.
+// Only sizes smaller than the image itself are produced.
+var ThumbnailSizes = []int{160, 480, 1024}
+
+// maxPixels bounds the images that are decoded, so a small file cannot expand into gigabytes.
+// Decoding takes about 4 bytes per pixel, plus the YCbCr planes of a JPEG.
+const maxPixels = 24_000_000
+
+// maxConcurrentDecodes bounds how many images are decoded at once, which with maxPixels caps
+// the memory used by uploads at a few hundred megabytes however many arrive together
+const maxConcurrentDecodes = 2
+
+var decodeSlots = make(chan struct{}, maxConcurrentDecodes)
+
+// blurhashSize is the size the image is shrunk to before computing its blurhash
+const blurhashSize = 32
+
+const thumbnailJPEGQuality = 80
+
+// Image is an uploaded image ready to be stored. Width, Height and Blurhash describe the upright
+// image and are zero when its format cannot be decoded, in which case there are no thumbnails.
+type Image struct {
+	Data       []byte
+	Width      int
+	Height     int
+	Blurhash   string
+	Thumbnails []*Thumbnail
+}
+
+// Thumbnail is a downscaled copy of an image that fits within Size x Size pixels
+type Thumbnail struct {
+	Size     int
+	Width    int
+	Height   int
+	MimeType string
+	Data     []byte
+}
+
+// ProcessImage strips EXIF and XMP metadata from the image and, for JPEG, PNG and GIF, decodes it
+// to record its dimensions, blurhash and thumbnails. Thumbnails are upright even when the original
+// relies on its EXIF orientation, and carry no metadata. JPEG, PNG and WebP images whose metadata
+// cannot be removed reliably are rejected with ErrMalformedImage.
+func ProcessImage(data []byte, mimeType string) (*Image, error) {
+	orientation := orientationNormal
+	var err error
+	switch mimeType {
+	case "image/jpeg":
+		data, orientation, err = stripJPEGMetadata(data)
+	case "image/png":
+		data, err = stripPNGMetadata(data)
+	case "image/webp":
+		data, err = stripWebPMetadata(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Image{Data: data}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width*config.Height > maxPixels {
+		// Unsupported or oversized images are stored without a preview
+		return result, nil
+	}
+
+	decodeSlots <- struct{}{}
+	defer func() { <-decodeSlots }()
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return result, nil
+	}
+
+	source := toRGBA(decoded)
+	result.Width, result.Height = source.Bounds().Dx(), source.Bounds().Dy()
+	if orientation >= orientationTranspose {
+		result.Width, result.Height = result.Height, result.Width
+	}
+
+	sizes := append([]int{}, ThumbnailSizes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	// Render the largest thumbnail first and shrink each one from the previous, which is much
+	// cheaper than averaging the full image every time. Only the small copies are turned upright,
+	// so the full image is never held twice.
+	for _, size := range sizes {
+		if size >= result.Width && size >= result.Height {
+			continue
+		}
+
+		width, height := fitWithin(source.Bounds().Dx(), source.Bounds().Dy(), size)
+		source = resize(source, width, height)
+
+		thumbnail, err := encodeThumbnail(orient(source, orientation), size)
+		if err != nil {
+			return nil, err
+		}
+		result.Thumbnails = append([]*Thumbnail{thumbnail}, result.Thumbnails...)
+	}
+
+	width, height := fitWithin(source.Bounds().Dx(), source.Bounds().Dy(), blurhashSize)
+	result.Blurhash = blurhash(orient(resize(source, width, height), orientation))
+
+	return result, nil
+}
+
+// encodeThumbnail writes opaque thumbnails as JPEG and keeps transparency with PNG
+func encodeThumbnail(img *image.RGBA, size int) (*Thumbnail, error) {
+	thumbnail := &Thumbnail{
+		Size:   size,
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+
+	var buf bytes.Buffer
+	if img.Opaque() {
+		thumbnail.MimeType = "image/jpeg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailJPEGQuality}); err != nil {
+			return nil, err
+		}
+	} else {
+		thumbnail.MimeType = "image/png"
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	}
+
+	thumbnail.Data = buf.Bytes()
+	return thumbnail, nil
+}
@@ -0,0 +1,184 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// pattern returns a width x height image whose pixels are set by fn
+func pattern(width, height int, fn func(x, y int) color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, fn(x, y))
+		}
+	}
+	return img
+}
+
+func TestOrient(t *testing.T) {
+	// A 3x2 image whose pixels are numbered in reading order:
+	//   1 2 3
+	//   4 5 6
+	src := pattern(3, 2, func(x, y int) color.RGBA { return color.RGBA{uint8(y*3 + x + 1), 0, 0, 255} })
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{1, [][]uint8{{1, 2, 3}, {4, 5, 6}}},
+		{2, [][]uint8{{3, 2, 1}, {6, 5, 4}}},
+		{3, [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+		{4, [][]uint8{{4, 5, 6}, {1, 2, 3}}},
+		{5, [][]uint8{{1, 4}, {2, 5}, {3, 6}}},
+		{6, [][]uint8{{4, 1}, {5, 2}, {6, 3}}},
+		{7, [][]uint8{{6, 3}, {5, 2}, {4, 1}}},
+		{8, [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+	}
+
+	for _, tt := range tests {
+		got := orient(src, tt.orientation)
+		if got.Bounds().Dy() != len(tt.want) || got.Bounds().Dx() != len(tt.want[0]) {
+			t.Errorf("orientation %d: size = %v, want %dx%d", tt.orientation, got.Bounds().Size(), len(tt.want[0]), len(tt.want))
+			continue
+		}
+		for y, row := range tt.want {
+			for x, want := range row {
+				if r := got.RGBAAt(x, y).R; r != want {
+					t.Errorf("orientation %d: pixel (%d, %d) = %d, want %d", tt.orientation, x, y, r, want)
+				}
+			}
+		}
+	}
+}
+
+func TestFitWithin(t *testing.T) {
+	tests := []struct {
+		width, height, size   int
+		wantWidth, wantHeight int
+	}{
+		{100, 50, 160, 100, 50},
+		{640, 480, 160, 160, 120},
+		{480, 640, 160, 120, 160},
+		{500, 500, 160, 160, 160},
+		{10000, 1, 160, 160, 1},
+	}
+
+	for _, tt := range tests {
+		width, height := fitWithin(tt.width, tt.height, tt.size)
+		if width != tt.wantWidth || height != tt.wantHeight {
+			t.Errorf("fitWithin(%d, %d, %d) = %d, %d, want %d, %d", tt.width, tt.height, tt.size, width, height, tt.wantWidth, tt.wantHeight)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	// Each 2x2 block averages to a known value
+	src := pattern(4, 4, func(x, y int) color.RGBA {
+		v := uint8(x%2*10 + y%2*20 + (x/2)*100 + (y/2)*50)
+		return color.RGBA{v, v, v, 255}
+	})
+
+	got := resize(src, 2, 2)
+	want := [][]uint8{{15, 115}, {65, 165}}
+	for y, row := range want {
+		for x, v := range row {
+			if p := got.RGBAAt(x, y); p.R != v || p.A != 255 {
+				t.Errorf("pixel (%d, %d) = %v, want %d", x, y, p, v)
+			}
+		}
+	}
+}
+
+func TestBlurhash(t *testing.T) {
+	// Expected hashes come from the reference encoder at https://github.com/woltapp/blurhash with 4x3 components
+	tests := []struct {
+		name string
+		img  *image.RGBA
+		want string
+	}{
+		{
+			"gradient",
+			pattern(32, 24, func(x, y int) color.RGBA {
+				return color.RGBA{uint8(x * 255 / 31), uint8(y * 255 / 23), 128, 255}
+			}),
+			"L$HewF2swxX8l}WDjte;gJfjfQfj",
+		},
+		{
+			"noise",
+			pattern(24, 32, func(x, y int) color.RGBA {
+				return color.RGBA{uint8((x*37 + y*11) % 256), uint8((x * y * 3) % 256), uint8(255 - x*8), 255}
+			}),
+			"L:HLJknaV~nonTjJf8a}V^agW:fj",
+		},
+		{
+			"solid",
+			pattern(8, 8, func(x, y int) color.RGBA { return color.RGBA{200, 100, 50, 255} }),
+			"LNM|T9}XfQ}X}XsofQsofQfQfQfQ",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := blurhash(tt.img); got != tt.want {
+			t.Errorf("%s: blurhash = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProcessImage(t *testing.T) {
+	t.Run("oriented JPEG", func(t *testing.T) {
+		processed, err := ProcessImage(readFixture(t, "gps.jpg"), "image/jpeg")
+		if err != nil {
+			t.Fatalf("ProcessImage: %v", err)
+		}
+		assertNoLocation(t, processed.Data)
+
+		// Stored as 64x48 with orientation 6, so it is 48x64 upright
+		if processed.Width != 48 || processed.Height != 64 {
+			t.Errorf("size = %dx%d, want 48x64", processed.Width, processed.Height)
+		}
+		if processed.Blurhash == "" || len(processed.Thumbnails) != 0 {
+			t.Errorf("blurhash = %q, %d thumbnails; want a blurhash and no thumbnails", processed.Blurhash, len(processed.Thumbnails))
+		}
+	})
+
+	t.Run("thumbnails", func(t *testing.T) {
+		var buf bytes.Buffer
+		img := pattern(600, 400, func(x, y int) color.RGBA { return color.RGBA{uint8(x), uint8(y), 0, 255} })
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+
+		processed, err := ProcessImage(buf.Bytes(), "image/png")
+		if err != nil {
+			t.Fatalf("ProcessImage: %v", err)
+		}
+		if processed.Width != 600 || processed.Height != 400 {
+			t.Errorf("size = %dx%d, want 600x400", processed.Width, processed.Height)
+		}
+
+		want := []struct{ size, width, height int }{{160, 160, 106}, {480, 480, 320}}
+		if len(processed.Thumbnails) != len(want) {
+			t.Fatalf("%d thumbnails, want %d", len(processed.Thumbnails), len(want))
+		}
+		for i, thumbnail := range processed.Thumbnails {
+			if thumbnail.Size != want[i].size || thumbnail.Width != want[i].width || thumbnail.Height != want[i].height {
+				t.Errorf("thumbnail %d = %d (%dx%d), want %d (%dx%d)", i, thumbnail.Size, thumbnail.Width, thumbnail.Height, want[i].size, want[i].width, want[i].height)
+			}
+			if thumbnail.MimeType != "image/jpeg" {
+				t.Errorf("thumbnail %d mime type = %s, want image/jpeg", i, thumbnail.MimeType)
+			}
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		data := readFixture(t, "gps.jpg")
+		if _, err := ProcessImage(data[:len(data)/2], "image/jpeg"); !errors.Is(err, ErrMalformedImage) {
+			t.Errorf("err = %v, want ErrMalformedImage", err)
+		}
+	})
+}
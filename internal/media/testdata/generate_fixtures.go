@@ -0,0 +1,155 @@
+//go:build ignore
+
+// Generates the GPS-tagged fixtures used by the media tests: go run generate_fixtures.go
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+)
+
+const xmp = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?><x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description xmlns:exif="http://ns.adobe.com/exif/1.0/" exif:GPSLatitude="52,22.2057N" exif:GPSLongitude="4,53.5760E"/></rdf:RDF></x:xmpmeta><?xpacket end="w"?>`
+
+// tiff builds a big-endian EXIF block with IFD0 {Make, Orientation, GPSInfo} and a GPS IFD
+func tiff(orientation uint16) []byte {
+	b := &bytes.Buffer{}
+	w := func(v any) { binary.Write(b, binary.BigEndian, v) }
+	camera := "GPS Fixture Camera\x00"
+	// layout: header 8, IFD0 at 8 with 3 entries: 2+36+4 = 42 -> ends at 50
+	// make string at 50 (19 bytes) -> 69, pad to 70; GPS IFD at 70 with 4 entries: 2+48+4=54 -> 124
+	// lat rationals at 124 (24 bytes), lon at 148 (24) -> 172
+	b.WriteString("MM")
+	w(uint16(42))
+	w(uint32(8))
+	w(uint16(3))
+	w(uint16(0x010F))
+	w(uint16(2))
+	w(uint32(len(camera)))
+	w(uint32(50))
+	w(uint16(0x0112))
+	w(uint16(3))
+	w(uint32(1))
+	w(orientation)
+	w(uint16(0))
+	w(uint16(0x8825))
+	w(uint16(4))
+	w(uint32(1))
+	w(uint32(70))
+	w(uint32(0))
+	b.WriteString(camera)
+	b.WriteByte(0)
+	w(uint16(4))
+	w(uint16(1))
+	w(uint16(2))
+	w(uint32(2))
+	b.WriteString("N\x00\x00\x00")
+	w(uint16(2))
+	w(uint16(5))
+	w(uint32(3))
+	w(uint32(124))
+	w(uint16(3))
+	w(uint16(2))
+	w(uint32(2))
+	b.WriteString("E\x00\x00\x00")
+	w(uint16(4))
+	w(uint16(5))
+	w(uint32(3))
+	w(uint32(148))
+	w(uint32(0))
+	for _, v := range []uint32{52, 1, 22, 1, 1234, 100, 4, 1, 53, 1, 3456, 100} {
+		w(v)
+	}
+	if b.Len() != 172 {
+		panic(b.Len())
+	}
+	return b.Bytes()
+}
+
+func gradient(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 255 / (w - 1)), uint8(y * 255 / (h - 1)), 128, 255})
+		}
+	}
+	return img
+}
+
+func segment(marker byte, payload []byte) []byte {
+	s := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(s[2:], uint16(len(payload)+2))
+	return append(s, payload...)
+}
+
+func chunk(kind string, payload []byte) []byte {
+	c := make([]byte, 8)
+	binary.BigEndian.PutUint32(c, uint32(len(payload)))
+	copy(c[4:], kind)
+	c = append(c, payload...)
+	crc := crc32.ChecksumIEEE(c[4:])
+	return binary.BigEndian.AppendUint32(c, crc)
+}
+
+func riffChunk(kind string, payload []byte) []byte {
+	c := make([]byte, 8)
+	copy(c, kind)
+	binary.LittleEndian.PutUint32(c[4:], uint32(len(payload)))
+	c = append(c, payload...)
+	if len(payload)%2 == 1 {
+		c = append(c, 0)
+	}
+	return c
+}
+
+func main() {
+	// JPEG: 64x48 stored sideways with orientation 6, EXIF with GPS, XMP, a comment, fill bytes
+	// before the quantisation tables and a second picture appended after the end of the image
+	var enc bytes.Buffer
+	jpeg.Encode(&enc, gradient(64, 48), &jpeg.Options{Quality: 90})
+	raw := enc.Bytes()
+	var j []byte
+	j = append(j, 0xFF, 0xD8)
+	j = append(j, segment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))...)
+	j = append(j, segment(0xE1, append([]byte("Exif\x00\x00"), tiff(6)...))...)
+	j = append(j, segment(0xE1, append([]byte("http://ns.adobe.com/xap/1.0/\x00"), xmp...))...)
+	j = append(j, segment(0xFE, []byte("Taken at 52.3701N 4.8926E"))...)
+	j = append(j, 0xFF, 0xFF, 0xFF) // fill bytes
+	j = append(j, raw[2:]...)
+	j = append(j, append([]byte{0xFF, 0xD8}, segment(0xE1, append([]byte("Exif\x00\x00"), tiff(1)...))...)...)
+	j = append(j, 0xFF, 0xD9)
+	os.WriteFile("gps.jpg", j, 0o644)
+
+	// PNG: 64x48 with an eXIf chunk holding GPS, XMP in iTXt and a tEXt comment
+	enc.Reset()
+	png.Encode(&enc, gradient(64, 48))
+	raw = enc.Bytes()
+	ihdrEnd := 8 + 25
+	var p []byte
+	p = append(p, raw[:ihdrEnd]...)
+	p = append(p, chunk("eXIf", tiff(1))...)
+	p = append(p, chunk("iTXt", append([]byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), xmp...))...)
+	p = append(p, chunk("tEXt", []byte("Comment\x00Taken at 52.3701N 4.8926E"))...)
+	p = append(p, raw[ihdrEnd:]...)
+	os.WriteFile("gps.png", p, 0o644)
+
+	// WebP: the classic 1x1 lossless image wrapped in VP8X with EXIF (GPS) and XMP chunks
+	lossless, _ := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+	vp8l := lossless[12:]
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x08 | 0x04 | 0x10 // EXIF, XMP, alpha
+	var w []byte
+	w = append(w, riffChunk("VP8X", vp8x)...)
+	w = append(w, vp8l...)
+	w = append(w, riffChunk("EXIF", tiff(1))...)
+	w = append(w, riffChunk("XMP ", []byte(xmp))...)
+	out := append([]byte("RIFF\x00\x00\x00\x00WEBP"), w...)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	os.WriteFile("gps.webp", out, 0o644)
+}
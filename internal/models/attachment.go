@@ -0,0 +1,66 @@
+package models
+
+// Content types of messages that carry an uploaded file
+const (
+	ContentTypeImageURL = "image_url"
+	ContentTypeFileURL  = "file_url"
+)
+
+// Metadata keys describing the uploaded file of an image_url or file_url message
+const (
+	AttachmentStorageKey = "storage_key"
+	AttachmentFileName   = "file_name"
+	AttachmentSize       = "size"
+	AttachmentMimeType   = "mime_type"
+	AttachmentChecksum   = "checksum_sha256"
+
+	// Images that could be decoded also record their upright dimensions, a blurhash placeholder
+	// and a list of thumbnails. Each thumbnail has the bounding box it was fitted into, its
+	// dimensions, byte size, MIME type and storage key.
+	AttachmentWidth      = "width"
+	AttachmentHeight     = "height"
+	AttachmentBlurhash   = "blurhash"
+	AttachmentThumbnails = "thumbnails"
+	ThumbnailMaxSize     = "max_size"
+)
+
+// AttachmentKeys returns the storage keys of the message's uploaded file and its thumbnails
+func (m *Message) AttachmentKeys() []string {
+	key := m.AttachmentKey()
+	if key == "" {
+		return nil
+	}
+
+	keys := []string{key}
+	for _, thumbnail := range m.Thumbnails() {
+		if thumbnailKey, ok := thumbnail[AttachmentStorageKey].(string); ok {
+			keys = append(keys, thumbnailKey)
+		}
+	}
+	return keys
+}
+
+// Thumbnails returns the thumbnail entries recorded in the message's metadata
+func (m *Message) Thumbnails() []map[string]any {
+	if m.Metadata == nil {
+		return nil
+	}
+
+	entries, _ := (*m.Metadata)[AttachmentThumbnails].([]any)
+	thumbnails := make([]map[string]any, 0, len(entries))
+	for _, entry := range entries {
+		if thumbnail, ok := entry.(map[string]any); ok {
+			thumbnails = append(thumbnails, thumbnail)
+		}
+	}
+	return thumbnails
+}
+
+// AttachmentKey returns the storage key of the message's uploaded file, or "" if it has none
+func (m *Message) AttachmentKey() string {
+	if m.Metadata == nil {
+		return ""
+	}
+	key, _ := (*m.Metadata)[AttachmentStorageKey].(string)
+	return key
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Inbox entry types
+const (
+	InboxEntryRoom = "room"
+	InboxEntryDM   = "dm"
+)
+
+// InboxEntry summarizes one of the user's conversations: a room they belong to or a DM thread.
+// For rooms Room is set; for DMs ThreadID and OtherUser are.
+type InboxEntry struct {
+	Type               string        `json:"type"`
+	Room               *Room         `json:"room,omitempty"`
+	ThreadID           *string       `json:"thread_id,omitempty"`
+	OtherUser          *PublicUser   `json:"other_user,omitempty"`
+	LastMessage        *ReplyPreview `json:"last_message"`
+	LastActivityAt     time.Time     `json:"last_activity_at"`
+	LastSeenMessageID  string        `json:"last_seen_message_id"`
+	UnreadCount        int64         `json:"unread_count"`
+	UnreadMentionCount int64         `json:"unread_mention_count"`
+}
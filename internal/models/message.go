@@ -26,27 +26,35 @@ func (m *Metadata) Scan(value interface{}) error {
 		*m = nil
 		return nil
 	}
-	
+
 	bytes, ok := value.([]byte)
 	if !ok {
 		return nil
 	}
-	
+
 	return json.Unmarshal(bytes, m)
 }
 
-// Message represents a message in the chat application (unified for rooms and direct messages)
+// Message represents a message in the chat application (unified for rooms and direct messages).
+// Room messages with a ParentMessageID are replies in the in-room thread started by that message.
 type Message struct {
-	MessageID   string     `json:"message_id" gorm:"column:message_id;type:char(36);primaryKey"`
-	RoomID      *string    `json:"room_id" gorm:"column:room_id;type:char(36);index:idx_messages_room_id_created_at,priority:1;constraint:OnDelete:CASCADE"`
-	ThreadID    *string    `json:"thread_id" gorm:"column:thread_id;type:char(36);index:idx_messages_thread_id_created_at,priority:1;constraint:OnDelete:CASCADE"`
-	SenderID    *string    `json:"sender_id" gorm:"column:sender_id;type:char(36);index:idx_messages_sender_id;constraint:OnDelete:SET NULL"`
-	ContentType string     `json:"content_type" gorm:"column:content_type;type:enum('text','image_url','file_url','system_notification','call_started','call_ended');not null;default:'text';index:idx_messages_content_type"`
-	Content     string     `json:"content" gorm:"column:content;type:text;not null"`
-	Metadata    *Metadata  `json:"metadata" gorm:"column:metadata;type:json"`
-	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at;not null;autoCreateTime;index:idx_messages_room_id_created_at,priority:2;index:idx_messages_thread_id_created_at,priority:2;index:idx_messages_created_at"`
-	UpdatedAt   *time.Time `json:"updated_at" gorm:"column:updated_at;type:timestamp"`
-	DeletedAt   *time.Time `json:"deleted_at" gorm:"column:deleted_at;type:timestamp"`
+	MessageID        string     `json:"message_id" gorm:"column:message_id;type:char(36);primaryKey"`
+	RoomID           *string    `json:"room_id" gorm:"column:room_id;type:char(36);index:idx_messages_room_id_created_at,priority:1;constraint:OnDelete:CASCADE"`
+	ThreadID         *string    `json:"thread_id" gorm:"column:thread_id;type:char(36);index:idx_messages_thread_id_created_at,priority:1;constraint:OnDelete:CASCADE"`
+	SenderID         *string    `json:"sender_id" gorm:"column:sender_id;type:char(36);index:idx_messages_sender_id;constraint:OnDelete:SET NULL"`
+	ContentType      string     `json:"content_type" gorm:"column:content_type;type:enum('text','image_url','file_url','system_notification','call_started','call_ended');not null;default:'text';index:idx_messages_content_type"`
+	Content          string     `json:"content" gorm:"column:content;type:text;not null;index:idx_messages_content_fulltext,class:FULLTEXT"`
+	Metadata         *Metadata  `json:"metadata" gorm:"column:metadata;type:json"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at;not null;autoCreateTime;index:idx_messages_room_id_created_at,priority:2;index:idx_messages_thread_id_created_at,priority:2;index:idx_messages_parent_message_id_created_at,priority:2;index:idx_messages_created_at"`
+	UpdatedAt        *time.Time `json:"updated_at" gorm:"column:updated_at;type:timestamp"`
+	DeletedAt        *time.Time `json:"deleted_at" gorm:"column:deleted_at;type:timestamp"`
+	ReplyToMessageID *string    `json:"reply_to_message_id" gorm:"column:reply_to_message_id;type:char(36);index:idx_messages_reply_to_message_id;constraint:OnDelete:SET NULL"`
+	ParentMessageID  *string    `json:"parent_message_id" gorm:"column:parent_message_id;type:char(36);index:idx_messages_parent_message_id_created_at,priority:1;constraint:OnDelete:CASCADE"`
+	AlsoSentToRoom   bool       `json:"also_sent_to_room" gorm:"column:also_sent_to_room;not null;default:false"`
+	ReplyCount       int        `json:"reply_count" gorm:"column:reply_count;not null;default:0"`
+	LastReplyAt      *time.Time `json:"last_reply_at" gorm:"column:last_reply_at;type:timestamp;null"`
+	PinnedAt         *time.Time `json:"pinned_at" gorm:"column:pinned_at;type:timestamp;null"`
+	PinnedBy         *string    `json:"pinned_by" gorm:"column:pinned_by;type:char(36);constraint:OnDelete:SET NULL"`
 }
 
 func (Message) TableName() string {
@@ -58,4 +66,42 @@ func (m *Message) BeforeCreate(tx *gorm.DB) (err error) {
 		m.MessageID = uuid.New().String()
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// ReplyPreviewLength is the number of characters of the parent message shown in a reply preview
+const ReplyPreviewLength = 100
+
+// ReplyPreview is a compact summary of the message a reply points at
+type ReplyPreview struct {
+	MessageID      string  `json:"message_id"`
+	SenderID       *string `json:"sender_id"`
+	SenderUsername *string `json:"sender_username"`
+	Content        string  `json:"content"`
+	ContentType    string  `json:"content_type"`
+	Deleted        bool    `json:"deleted"`
+}
+
+// NewReplyPreview summarizes a parent message, truncating its content
+func NewReplyPreview(parent *Message, senderUsername *string) *ReplyPreview {
+	content := []rune(parent.Content)
+	preview := string(content)
+	if len(content) > ReplyPreviewLength {
+		preview = string(content[:ReplyPreviewLength]) + "…"
+	}
+
+	return &ReplyPreview{
+		MessageID:      parent.MessageID,
+		SenderID:       parent.SenderID,
+		SenderUsername: senderUsername,
+		Content:        preview,
+		ContentType:    parent.ContentType,
+		Deleted:        parent.DeletedAt != nil,
+	}
+}
+
+// MessageSearchHit is a message matched by a full-text search
+type MessageSearchHit struct {
+	Message   `gorm:"embedded"`
+	RoomName  *string `json:"room_name"`
+	Relevance float64 `json:"relevance"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MessageEdit keeps a prior version of an edited message
+type MessageEdit struct {
+	EditID    string    `json:"edit_id" gorm:"column:edit_id;type:char(36);primaryKey"`
+	MessageID string    `json:"message_id" gorm:"column:message_id;type:char(36);not null;index:idx_message_edits_message_id_edited_at,priority:1;constraint:OnDelete:CASCADE"`
+	Content   string    `json:"content" gorm:"column:content;type:text;not null"`
+	EditedBy  string    `json:"edited_by" gorm:"column:edited_by;type:char(36);not null"`
+	EditedAt  time.Time `json:"edited_at" gorm:"column:edited_at;not null;index:idx_message_edits_message_id_edited_at,priority:2"`
+}
+
+func (MessageEdit) TableName() string {
+	return "message_edits"
+}
+
+func (e *MessageEdit) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.EditID == "" {
+		e.EditID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Mention tokens that address more than one member of a room
+const (
+	MentionHere     = "here"
+	MentionEveryone = "everyone"
+)
+
+// MessageMention records that a room message mentioned a user, directly or through @here / @everyone
+type MessageMention struct {
+	MessageID string    `json:"message_id" gorm:"column:message_id;type:char(36);primaryKey;constraint:OnDelete:CASCADE"`
+	UserID    string    `json:"user_id" gorm:"column:user_id;type:char(36);primaryKey;index:idx_message_mentions_user_id_created_at,priority:1;constraint:OnDelete:CASCADE"`
+	RoomID    string    `json:"room_id" gorm:"column:room_id;type:char(36);not null;index:idx_message_mentions_room_id;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;not null;index:idx_message_mentions_user_id_created_at,priority:2"`
+}
+
+func (MessageMention) TableName() string {
+	return "message_mentions"
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MessageReaction is a single user's emoji reaction to a message
+type MessageReaction struct {
+	ReactionID string    `json:"reaction_id" gorm:"column:reaction_id;type:char(36);primaryKey"`
+	MessageID  string    `json:"message_id" gorm:"column:message_id;type:char(36);not null;uniqueIndex:unique_message_reaction;constraint:OnDelete:CASCADE"`
+	UserID     string    `json:"user_id" gorm:"column:user_id;type:char(36);not null;uniqueIndex:unique_message_reaction;index:idx_message_reactions_user_id;constraint:OnDelete:CASCADE"`
+	Emoji      string    `json:"emoji" gorm:"column:emoji;type:varchar(64);not null;uniqueIndex:unique_message_reaction"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (MessageReaction) TableName() string {
+	return "message_reactions"
+}
+
+func (r *MessageReaction) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ReactionID == "" {
+		r.ReactionID = uuid.New().String()
+	}
+	return nil
+}
+
+// ReactionSummary aggregates the reactions with one emoji on a message
+type ReactionSummary struct {
+	MessageID string `json:"-"`
+	Emoji     string `json:"emoji"`
+	Count     int64  `json:"count"`
+	Reacted   bool   `json:"reacted"`
+}
+
+// MessageView is a message as returned to a particular user, with its aggregated reactions
+// and a preview of the message it replies to
+type MessageView struct {
+	Message
+	Reactions []*ReactionSummary `json:"reactions"`
+	ReplyTo   *ReplyPreview      `json:"reply_to,omitempty"`
+}
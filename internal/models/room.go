@@ -1,18 +1,65 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Room types: a space is a container whose channels are regular rooms pointing at it
+const (
+	RoomTypeRoom  = "room"
+	RoomTypeSpace = "space"
+)
 
 type Room struct {
-	RoomID      string     `json:"room_id" gorm:"type:char(36):column:room_id;primary_key;autoIncrement"`
-	Name        string     `json:"name" gorm:"column:name;type:varchar(100);not null;index:idx_rooms_name"`
-	Description string     `json:"description" gorm:"column:description;type:text"`
-	IsPrivate   bool       `json:"is_private" gorm:"column:is_private;default:false"`
-	CreatedBy   uint64     `json:"created_by" gorm:"column:created_by;not null;index:idx_rooms_created_by_user_id;constraint:OnDelete:CASCADE"`
-	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt   time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	RoomID        string     `json:"room_id" gorm:"column:room_id;type:char(36);primaryKey"`
+	Name          string     `json:"name" gorm:"column:name;type:varchar(100);not null;index:idx_rooms_name"`
+	Description   string     `json:"description" gorm:"column:description;type:text"`
+	IsPrivate     bool       `json:"is_private" gorm:"column:is_private;default:false"`
+	RoomType      string     `json:"room_type" gorm:"column:room_type;type:enum('room','space');not null;default:'room'"`
+	ParentSpaceID *string    `json:"parent_space_id" gorm:"column:parent_space_id;type:char(36);index:idx_rooms_parent_space_id;constraint:OnDelete:CASCADE"`
+	CategoryID    *string    `json:"category_id" gorm:"column:category_id;type:char(36);index:idx_rooms_category_id;constraint:OnDelete:SET NULL"`
+	Position      int        `json:"position" gorm:"column:position;not null;default:0"`
+	// AnnouncementOnly restricts posting to members who can manage the room or its members
+	AnnouncementOnly bool `json:"announcement_only" gorm:"column:announcement_only;not null;default:false"`
+	// SlowModeSeconds is the minimum interval between two messages from the same member; 0 disables it
+	SlowModeSeconds int `json:"slow_mode_seconds" gorm:"column:slow_mode_seconds;not null;default:0"`
+	CreatedBy     string     `json:"created_by" gorm:"column:created_by;type:char(36);not null;index:idx_rooms_created_by_user_id;constraint:OnDelete:CASCADE"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 	LastMessageAt *time.Time `json:"last_message_at" gorm:"column:last_message_at;type:timestamp;null"`
+	ArchivedAt    *time.Time `json:"archived_at" gorm:"column:archived_at;type:timestamp;null"`
 }
 
 func (Room) TableName() string {
 	return "rooms"
-}
\ No newline at end of file
+}
+
+func (r *Room) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.RoomID == "" {
+		r.RoomID = uuid.New().String()
+	}
+	if r.RoomType == "" {
+		r.RoomType = RoomTypeRoom
+	}
+	return nil
+}
+
+// IsSpace reports whether the room is a space container rather than a chat room
+func (r *Room) IsSpace() bool {
+	return r.RoomType == RoomTypeSpace
+}
+
+// IsArchived reports whether the room has been archived and is read-only
+func (r *Room) IsArchived() bool {
+	return r.ArchivedAt != nil
+}
+
+// RoomDirectoryEntry is a public room as listed in the room directory
+type RoomDirectoryEntry struct {
+	Room        `gorm:"embedded"`
+	MemberCount int64 `json:"member_count"`
+	Joined      bool  `json:"joined"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoomInvitation is a pending request for a user to join a room
+type RoomInvitation struct {
+	InvitationID string    `json:"invitation_id" gorm:"column:invitation_id;type:char(36);primaryKey"`
+	RoomID       string    `json:"room_id" gorm:"column:room_id;type:char(36);not null;index:idx_room_invitations_room_id;constraint:OnDelete:CASCADE"`
+	InviterID    string    `json:"inviter_id" gorm:"column:inviter_id;type:char(36);not null;constraint:OnDelete:CASCADE"`
+	InviteeID    string    `json:"invitee_id" gorm:"column:invitee_id;type:char(36);not null;index:idx_room_invitations_invitee_id;constraint:OnDelete:CASCADE"`
+	Status       string    `json:"status" gorm:"column:status;type:enum('pending', 'accepted', 'declined');default:'pending'"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// RoomInvitationWithRoom combines an invitation with the room it is for
+type RoomInvitationWithRoom struct {
+	InvitationID string    `json:"invitation_id"`
+	RoomID       string    `json:"room_id"`
+	InviterID    string    `json:"inviter_id"`
+	InviteeID    string    `json:"invitee_id"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Room         Room      `json:"room" gorm:"embedded;embeddedPrefix:room_"`
+}
+
+func (RoomInvitation) TableName() string {
+	return "room_invitations"
+}
+
+func (i *RoomInvitation) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.InvitationID == "" {
+		i.InvitationID = uuid.New().String()
+	}
+	return nil
+}
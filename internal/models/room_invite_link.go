@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoomInviteLink is a shareable code that lets anyone holding it join a room
+type RoomInviteLink struct {
+	InviteLinkID string     `json:"invite_link_id" gorm:"column:invite_link_id;type:char(36);primaryKey"`
+	RoomID       string     `json:"room_id" gorm:"column:room_id;type:char(36);not null;index:idx_room_invite_links_room_id;constraint:OnDelete:CASCADE"`
+	Code         string     `json:"code" gorm:"column:code;type:varchar(32);not null;uniqueIndex:idx_room_invite_links_code"`
+	CreatedBy    string     `json:"created_by" gorm:"column:created_by;type:char(36);not null;constraint:OnDelete:CASCADE"`
+	ExpiresAt    *time.Time `json:"expires_at" gorm:"column:expires_at;type:timestamp;null"`
+	MaxUses      *int       `json:"max_uses" gorm:"column:max_uses"`
+	Uses         int        `json:"uses" gorm:"column:uses;not null;default:0"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty" gorm:"column:revoked_at;type:timestamp;null"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (RoomInviteLink) TableName() string {
+	return "room_invite_links"
+}
+
+func (l *RoomInviteLink) BeforeCreate(tx *gorm.DB) (err error) {
+	if l.InviteLinkID == "" {
+		l.InviteLinkID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsUsable reports whether the link can still be redeemed at the given time
+func (l *RoomInviteLink) IsUsable(now time.Time) bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	if l.ExpiresAt != nil && !l.ExpiresAt.After(now) {
+		return false
+	}
+	if l.MaxUses != nil && l.Uses >= *l.MaxUses {
+		return false
+	}
+	return true
+}
@@ -2,18 +2,53 @@ package models
 
 import (
 	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Room member roles, ordered from least to most privileged.
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+	RoleOwner  = "owner"
 )
 
 // RoomMember represents a member of a room in the chat application.
 type RoomMember struct {
-	RoomMemberID       string    `gorm:"column:room_member_id;type:char(36);primaryKey"`
-	RoomID             string    `gorm:"column:room_id;type:char(36);not null;index:idx_room_members_room_id;constraint:OnDelete:CASCADE"`
-	UserID             string    `gorm:"column:user_id;type:char(36);not null;index:idx_room_members_user_id;constraint:OnDelete:CASCADE"`
-	Role               string    `gorm:"column:role;type:enum('member', 'admin', 'owner');default:'member';not null"`
-	JoinedAt           time.Time `gorm:"column:joined_at;autoCreateTime"`
-	LastSeenMessageID  string    `gorm:"column:last_seen_message_id;type:char(36);index:idx_room_members_last_seen_message_id;constraint:OnDelete:SET NULL"`
+	RoomMemberID      string    `json:"room_member_id" gorm:"column:room_member_id;type:char(36);primaryKey"`
+	RoomID            string    `json:"room_id" gorm:"column:room_id;type:char(36);not null;index:idx_room_members_room_id;uniqueIndex:unique_room_member;constraint:OnDelete:CASCADE"`
+	UserID            string    `json:"user_id" gorm:"column:user_id;type:char(36);not null;index:idx_room_members_user_id;uniqueIndex:unique_room_member;constraint:OnDelete:CASCADE"`
+	Role              string    `json:"role" gorm:"column:role;type:enum('member', 'admin', 'owner');default:'member';not null"`
+	RoleID            *string   `json:"role_id" gorm:"column:role_id;type:char(36);index:idx_room_members_role_id;constraint:OnDelete:SET NULL"`
+	JoinedAt          time.Time `json:"joined_at" gorm:"column:joined_at;autoCreateTime"`
+	LastSeenMessageID string    `json:"last_seen_message_id" gorm:"column:last_seen_message_id;type:char(36);index:idx_room_members_last_seen_message_id;constraint:OnDelete:SET NULL"`
 }
 
 func (RoomMember) TableName() string {
 	return "room_members"
-}
\ No newline at end of file
+}
+
+func (m *RoomMember) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.RoomMemberID == "" {
+		m.RoomMemberID = uuid.New().String()
+	}
+	return nil
+}
+
+// RoomMemberWithUser combines room membership data with the member's public user info
+type RoomMemberWithUser struct {
+	RoomMemberID string     `json:"room_member_id"`
+	RoomID       string     `json:"room_id"`
+	UserID       string     `json:"user_id"`
+	Role         string     `json:"role"`
+	RoleID       *string    `json:"role_id"`
+	JoinedAt     time.Time  `json:"joined_at"`
+	User         PublicUser `json:"user" gorm:"embedded;embeddedPrefix:user_"`
+}
+
+// ReadMarker is how far a participant has read in a room or DM thread
+type ReadMarker struct {
+	UserID            string `json:"user_id"`
+	LastSeenMessageID string `json:"last_seen_message_id"`
+}
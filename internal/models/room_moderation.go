@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoomBan keeps a user out of a room until it expires; a nil ExpiresAt means the ban is permanent
+type RoomBan struct {
+	BanID     string     `json:"ban_id" gorm:"column:ban_id;type:char(36);primaryKey"`
+	RoomID    string     `json:"room_id" gorm:"column:room_id;type:char(36);not null;index:idx_room_bans_room_user,priority:1;constraint:OnDelete:CASCADE"`
+	UserID    string     `json:"user_id" gorm:"column:user_id;type:char(36);not null;index:idx_room_bans_room_user,priority:2;constraint:OnDelete:CASCADE"`
+	BannedBy  string     `json:"banned_by" gorm:"column:banned_by;type:char(36);not null"`
+	Reason    string     `json:"reason" gorm:"column:reason;type:varchar(500);not null"`
+	ExpiresAt *time.Time `json:"expires_at" gorm:"column:expires_at;type:timestamp;null"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (RoomBan) TableName() string {
+	return "room_bans"
+}
+
+func (b *RoomBan) BeforeCreate(tx *gorm.DB) (err error) {
+	if b.BanID == "" {
+		b.BanID = uuid.New().String()
+	}
+	return nil
+}
+
+// RoomMute stops a member from sending messages in a room until it expires
+type RoomMute struct {
+	MuteID    string    `json:"mute_id" gorm:"column:mute_id;type:char(36);primaryKey"`
+	RoomID    string    `json:"room_id" gorm:"column:room_id;type:char(36);not null;index:idx_room_mutes_room_user,priority:1;constraint:OnDelete:CASCADE"`
+	UserID    string    `json:"user_id" gorm:"column:user_id;type:char(36);not null;index:idx_room_mutes_room_user,priority:2;constraint:OnDelete:CASCADE"`
+	MutedBy   string    `json:"muted_by" gorm:"column:muted_by;type:char(36);not null"`
+	Reason    string    `json:"reason" gorm:"column:reason;type:varchar(500);not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"column:expires_at;type:timestamp;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (RoomMute) TableName() string {
+	return "room_mutes"
+}
+
+func (m *RoomMute) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.MuteID == "" {
+		m.MuteID = uuid.New().String()
+	}
+	return nil
+}
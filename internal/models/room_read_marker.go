@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RoomReadMarker holds the read marker of a space member in a public channel they reach through
+// the space. They have no member row in the channel to keep it on, and creating one would give
+// them a channel role that outlives their space membership.
+type RoomReadMarker struct {
+	RoomID            string    `json:"room_id" gorm:"column:room_id;type:char(36);primaryKey;constraint:OnDelete:CASCADE"`
+	UserID            string    `json:"user_id" gorm:"column:user_id;type:char(36);primaryKey;index:idx_room_read_markers_user_id;constraint:OnDelete:CASCADE"`
+	LastSeenMessageID string    `json:"last_seen_message_id" gorm:"column:last_seen_message_id;type:char(36);constraint:OnDelete:SET NULL"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (RoomReadMarker) TableName() string {
+	return "room_read_markers"
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Permission is a named capability that can be granted within a room
+type Permission string
+
+const (
+	PermissionSendMessages    Permission = "send_messages"
+	PermissionPinMessages     Permission = "pin_messages"
+	PermissionDeleteMessages  Permission = "delete_messages"
+	PermissionInviteMembers   Permission = "invite_members"
+	PermissionManageMembers   Permission = "manage_members"
+	PermissionManageRoom      Permission = "manage_room"
+	PermissionMentionEveryone Permission = "mention_everyone"
+)
+
+// AllPermissions lists every permission a room role can carry
+var AllPermissions = PermissionSet{
+	PermissionSendMessages,
+	PermissionPinMessages,
+	PermissionDeleteMessages,
+	PermissionInviteMembers,
+	PermissionManageMembers,
+	PermissionManageRoom,
+	PermissionMentionEveryone,
+}
+
+// PermissionSet is a list of permissions stored as a JSON array
+type PermissionSet []Permission
+
+// Value implements the driver.Valuer interface for database storage
+func (p PermissionSet) Value() (driver.Value, error) {
+	if p == nil {
+		return json.Marshal([]Permission{})
+	}
+	return json.Marshal([]Permission(p))
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (p *PermissionSet) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, p)
+}
+
+// Has reports whether the set contains the permission
+func (p PermissionSet) Has(permission Permission) bool {
+	for _, granted := range p {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate rejects unknown permission names
+func (p PermissionSet) Validate() error {
+	for _, permission := range p {
+		if !AllPermissions.Has(permission) {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+	return nil
+}
+
+// DefaultPermissions returns the permissions implied by a fixed member role when no custom role is assigned.
+// Regular members may only invite others into public rooms, and cannot notify the whole room with
+// @here or @everyone.
+func DefaultPermissions(role string, room *Room) PermissionSet {
+	switch role {
+	case RoleOwner, RoleAdmin:
+		return AllPermissions
+	default:
+		if room != nil && !room.IsPrivate {
+			return PermissionSet{PermissionSendMessages, PermissionInviteMembers}
+		}
+		return PermissionSet{PermissionSendMessages}
+	}
+}
+
+// EffectivePermissions resolves what a member may do in a room. Owners can always do everything;
+// otherwise an assigned custom role replaces the defaults of the member's fixed role.
+func EffectivePermissions(room *Room, member *RoomMember, customRole *RoomRole) PermissionSet {
+	if member.Role == RoleOwner {
+		return AllPermissions
+	}
+	if customRole != nil {
+		return customRole.Permissions
+	}
+	return DefaultPermissions(member.Role, room)
+}
+
+// RoomRole is a named, per-room set of permissions that can be assigned to members
+type RoomRole struct {
+	RoleID      string        `json:"role_id" gorm:"column:role_id;type:char(36);primaryKey"`
+	RoomID      string        `json:"room_id" gorm:"column:room_id;type:char(36);not null;uniqueIndex:unique_room_role_name;constraint:OnDelete:CASCADE"`
+	Name        string        `json:"name" gorm:"column:name;type:varchar(50);not null;uniqueIndex:unique_room_role_name"`
+	Permissions PermissionSet `json:"permissions" gorm:"column:permissions;type:json;not null"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time     `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (RoomRole) TableName() string {
+	return "room_roles"
+}
+
+func (r *RoomRole) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.RoleID == "" {
+		r.RoleID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scheduled message statuses. Sent messages are removed, so only undelivered ones remain.
+const (
+	ScheduledStatusPending = "pending"
+	ScheduledStatusSending = "sending"
+	ScheduledStatusFailed  = "failed"
+)
+
+// ScheduledMessage is a message written now to be sent to a room or DM thread at SendAt.
+// Once sent, the new message takes the scheduled message's ID.
+type ScheduledMessage struct {
+	ScheduledMessageID string    `json:"scheduled_message_id" gorm:"column:scheduled_message_id;type:char(36);primaryKey"`
+	SenderID           string    `json:"sender_id" gorm:"column:sender_id;type:char(36);not null;index:idx_scheduled_messages_sender_id_send_at,priority:1;constraint:OnDelete:CASCADE"`
+	RoomID             *string   `json:"room_id" gorm:"column:room_id;type:char(36);index:idx_scheduled_messages_room_id;constraint:OnDelete:CASCADE"`
+	ThreadID           *string   `json:"thread_id" gorm:"column:thread_id;type:char(36);index:idx_scheduled_messages_thread_id;constraint:OnDelete:CASCADE"`
+	Content            string    `json:"content" gorm:"column:content;type:text;not null"`
+	ReplyToMessageID   *string   `json:"reply_to_message_id" gorm:"column:reply_to_message_id;type:char(36)"`
+	ParentMessageID    *string   `json:"parent_message_id" gorm:"column:parent_message_id;type:char(36)"`
+	AlsoSendToRoom     bool      `json:"also_send_to_room" gorm:"column:also_send_to_room;not null;default:false"`
+	SendAt             time.Time `json:"send_at" gorm:"column:send_at;not null;index:idx_scheduled_messages_status_send_at,priority:2;index:idx_scheduled_messages_sender_id_send_at,priority:2"`
+	Status             string    `json:"status" gorm:"column:status;type:enum('pending', 'sending', 'failed');default:'pending';not null;index:idx_scheduled_messages_status_send_at,priority:1"`
+	FailureReason      *string   `json:"failure_reason" gorm:"column:failure_reason;type:varchar(255)"`
+	CreatedAt          time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt          time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (ScheduledMessage) TableName() string {
+	return "scheduled_messages"
+}
+
+func (m *ScheduledMessage) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ScheduledMessageID == "" {
+		m.ScheduledMessageID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SpaceCategory groups a space's channels under a heading
+type SpaceCategory struct {
+	CategoryID string    `json:"category_id" gorm:"column:category_id;type:char(36);primaryKey"`
+	SpaceID    string    `json:"space_id" gorm:"column:space_id;type:char(36);not null;index:idx_space_categories_space_id;constraint:OnDelete:CASCADE"`
+	Name       string    `json:"name" gorm:"column:name;type:varchar(100);not null"`
+	Position   int       `json:"position" gorm:"column:position;not null;default:0"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (SpaceCategory) TableName() string {
+	return "space_categories"
+}
+
+func (c *SpaceCategory) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.CategoryID == "" {
+		c.CategoryID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ThreadFollower subscribes a user to the replies of an in-room thread
+type ThreadFollower struct {
+	MessageID string    `json:"message_id" gorm:"column:message_id;type:char(36);primaryKey;constraint:OnDelete:CASCADE"`
+	UserID    string    `json:"user_id" gorm:"column:user_id;type:char(36);primaryKey;index:idx_thread_followers_user_id;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (ThreadFollower) TableName() string {
+	return "thread_followers"
+}
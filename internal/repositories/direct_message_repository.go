@@ -49,5 +49,42 @@ func (r *DirectMessageRepository) CreateDirectMessageThread(user1ID, user2ID str
 	return &newThread, nil
 }
 
+func (r *DirectMessageRepository) FindThreadByID(threadID string) (*models.DirectMessageThread, error) {
+	var thread models.DirectMessageThread
+	err := r.db.Where("thread_id = ?", threadID).First(&thread).Error
+	if err != nil {
+		return nil, err
+	}
+	return &thread, nil
+}
+
+// GetThreadsForUser returns every DM thread the user takes part in
+func (r *DirectMessageRepository) GetThreadsForUser(userID string) ([]*models.DirectMessageThread, error) {
+	var threads []*models.DirectMessageThread
+	err := r.db.Where("user1_id = ? OR user2_id = ?", userID, userID).Find(&threads).Error
+	if err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
+
+// AdvanceLastSeen moves the participant's read marker in the thread to the message unless it
+// already points at the same or a newer one. Reports whether the marker moved.
+func (r *DirectMessageRepository) AdvanceLastSeen(thread *models.DirectMessageThread, userID string, message *models.Message) (bool, error) {
+	column := "user1_last_seen_message_id"
+	if thread.User2ID == userID {
+		column = "user2_last_seen_message_id"
+	}
+
+	result := r.db.Model(&models.DirectMessageThread{}).
+		Where("thread_id = ?", thread.DirectMessageThreadID).
+		Where(behindMarkerCondition(column), message.CreatedAt, message.CreatedAt, message.MessageID).
+		UpdateColumn(column, message.MessageID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 //tomorrow work on messages and retrieval for thread. then work on
 //websocket message routing
\ No newline at end of file
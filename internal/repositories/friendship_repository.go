@@ -17,9 +17,17 @@ func NewFriendRepository() *FriendRepository {
 	}
 }
 
-func (r *FriendRepository) GetFriends(userID string) ([]*models.PublicUser, error) {
+// Sort orders supported by GetFriends
+const (
+	FriendSortUsername = "username"
+	FriendSortActivity = "activity"
+)
+
+// GetFriends returns the user's friends with their DM thread, ordered by username or, for
+// FriendSortActivity, by the most recent message in that thread
+func (r *FriendRepository) GetFriends(userID, sort string) ([]*models.PublicUser, error) {
     var users []*models.PublicUser
-    err := r.db.Table("friendships f").
+    tx := r.db.Table("friendships f").
         Select(`u.user_id, u.username, u.email, u.display_name, u.avatar_url, u.status, u.last_active_at, 
                 u.created_at, u.updated_at, u.deleted_at, dm.thread_id as dm_thread_id`).
         Joins(`JOIN users u ON (
@@ -32,10 +40,14 @@ func (r *FriendRepository) GetFriends(userID string) ([]*models.PublicUser, erro
             (dm.user1_id = ? AND dm.user2_id = u.user_id) OR 
             (dm.user2_id = ? AND dm.user1_id = u.user_id)
         )`, userID, userID).
-        Where("(f.user1_id = ? OR f.user2_id = ?) AND u.deleted_at IS NULL", userID, userID).
-        Order("u.username ASC").
-        Scan(&users).Error
-    
+        Where("(f.user1_id = ? OR f.user2_id = ?) AND u.deleted_at IS NULL", userID, userID)
+
+    if sort == FriendSortActivity {
+        tx = tx.Order("dm.last_message_at IS NULL").Order("dm.last_message_at DESC")
+    }
+
+    err := tx.Order("u.username ASC").Scan(&users).Error
+
     if err != nil {
         return nil, err
     }
@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InboxRepository answers the per-conversation questions behind the inbox in batches,
+// so building it costs a fixed number of queries however many conversations the user has
+type InboxRepository struct {
+	db *gorm.DB
+}
+
+func NewInboxRepository() *InboxRepository {
+	return &InboxRepository{
+		db: db.GetDB(),
+	}
+}
+
+// LatestMessage identifies the newest message of a conversation
+type LatestMessage struct {
+	ConversationID string
+	MessageID      string
+	CreatedAt      time.Time
+}
+
+type conversationCount struct {
+	ConversationID string
+	Count          int64
+}
+
+// roomReadStates is a derived table of every room the user receives messages from, with their read
+// marker and when they joined. It takes the user ID twice and the current time. Besides direct
+// memberships it has the public channels of the user's spaces that they are not banned from, as in
+// RoomRepository.GetAudienceUserIDs; in those the marker is kept in room_read_markers and the user
+// counts as joined when they joined the space.
+const roomReadStates = `(
+	SELECT rm.room_id, rm.last_seen_message_id, rm.joined_at FROM room_members rm WHERE rm.user_id = ?
+	UNION ALL
+	SELECT c.room_id, mk.last_seen_message_id, sm.joined_at FROM rooms c
+	JOIN room_members sm ON sm.room_id = c.parent_space_id AND sm.user_id = ?
+	LEFT JOIN room_read_markers mk ON mk.room_id = c.room_id AND mk.user_id = sm.user_id
+	WHERE c.is_private = false
+	AND NOT EXISTS (SELECT 1 FROM room_members cm WHERE cm.room_id = c.room_id AND cm.user_id = sm.user_id)
+	AND NOT ` + spaceMemberBannedFromChannel + `
+) rs`
+
+// GetRooms returns the rooms and channels the user receives messages from, directly or through a space.
+// Spaces hold no messages and are left out.
+func (r *InboxRepository) GetRooms(userID string) ([]*models.Room, error) {
+	var rooms []*models.Room
+	err := r.db.Table("rooms r").
+		Select("r.*").
+		Joins("JOIN "+roomReadStates+" ON rs.room_id = r.room_id", userID, userID, time.Now()).
+		Where("r.room_type <> ?", models.RoomTypeSpace).
+		Find(&rooms).Error
+	if err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// unreadAfterMarker matches messages m newer than the read marker message seen. Without a marker,
+// room messages count from when the member joined (rs.joined_at) and DM messages all count.
+const unreadAfterMarker = `(
+	(seen.message_id IS NULL AND %s)
+	OR m.created_at > seen.created_at
+	OR (m.created_at = seen.created_at AND m.message_id > seen.message_id)
+)`
+
+// GetLatestRoomMessages returns the newest main-timeline message of each room, keyed by room ID
+func (r *InboxRepository) GetLatestRoomMessages(roomIDs []string) (map[string]*LatestMessage, error) {
+	return r.getLatestMessages("rooms", "room_id", roomIDs,
+		"l.room_id = c.room_id AND (l.parent_message_id IS NULL OR l.also_sent_to_room = true)")
+}
+
+// GetLatestThreadMessages returns the newest message of each DM thread, keyed by thread ID
+func (r *InboxRepository) GetLatestThreadMessages(threadIDs []string) (map[string]*LatestMessage, error) {
+	return r.getLatestMessages("direct_message_threads", "thread_id", threadIDs, "l.thread_id = c.thread_id")
+}
+
+func (r *InboxRepository) getLatestMessages(table, idColumn string, ids []string, belongs string) (map[string]*LatestMessage, error) {
+	latest := make(map[string]*LatestMessage, len(ids))
+	if len(ids) == 0 {
+		return latest, nil
+	}
+
+	var rows []*LatestMessage
+	err := r.db.Table(table+" c").
+		Select("c."+idColumn+" AS conversation_id, m.message_id, m.created_at").
+		Joins(`JOIN messages m ON m.message_id = (
+			SELECT l.message_id FROM messages l WHERE `+belongs+`
+			ORDER BY l.created_at DESC, l.message_id DESC LIMIT 1
+		)`).
+		Where("c."+idColumn+" IN ?", ids).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		latest[row.ConversationID] = row
+	}
+	return latest, nil
+}
+
+// GetRoomLastSeen returns the user's read marker in each room they receive messages from, keyed by room ID
+func (r *InboxRepository) GetRoomLastSeen(userID string) (map[string]string, error) {
+	var rows []struct {
+		RoomID            string
+		LastSeenMessageID *string
+	}
+	err := r.db.Table(roomReadStates, userID, userID, time.Now()).
+		Select("rs.room_id, rs.last_seen_message_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lastSeen := make(map[string]string, len(rows))
+	for _, row := range rows {
+		if row.LastSeenMessageID != nil {
+			lastSeen[row.RoomID] = *row.LastSeenMessageID
+		}
+	}
+	return lastSeen, nil
+}
+
+// CountUnreadRoomMessages counts, per room, the live main-timeline messages from others
+// after the user's read marker
+func (r *InboxRepository) CountUnreadRoomMessages(userID string) (map[string]int64, error) {
+	query := r.db.Table("messages m").
+		Select("m.room_id AS conversation_id, COUNT(*) AS count").
+		Joins("JOIN "+roomReadStates+" ON rs.room_id = m.room_id", userID, userID, time.Now()).
+		Joins("LEFT JOIN messages seen ON seen.message_id = rs.last_seen_message_id").
+		Where("m.parent_message_id IS NULL OR m.also_sent_to_room = ?", true).
+		Where("m.deleted_at IS NULL AND NOT (m.sender_id <=> ?)", userID).
+		Where(fmt.Sprintf(unreadAfterMarker, "m.created_at > rs.joined_at")).
+		Group("m.room_id")
+	return scanCounts(query)
+}
+
+// CountUnreadRoomMentions counts, per room, the live messages mentioning the user after their read marker
+func (r *InboxRepository) CountUnreadRoomMentions(userID string) (map[string]int64, error) {
+	query := r.db.Table("message_mentions mm").
+		Select("mm.room_id AS conversation_id, COUNT(*) AS count").
+		Joins("JOIN messages m ON m.message_id = mm.message_id").
+		Joins("JOIN "+roomReadStates+" ON rs.room_id = mm.room_id", userID, userID, time.Now()).
+		Joins("LEFT JOIN messages seen ON seen.message_id = rs.last_seen_message_id").
+		Where("mm.user_id = ? AND m.deleted_at IS NULL", userID).
+		Where(fmt.Sprintf(unreadAfterMarker, "m.created_at > rs.joined_at")).
+		Group("mm.room_id")
+	return scanCounts(query)
+}
+
+// CountUnreadThreadMessages counts, per DM thread, the live messages from the other participant
+// after the user's read marker
+func (r *InboxRepository) CountUnreadThreadMessages(userID string) (map[string]int64, error) {
+	query := r.db.Table("messages m").
+		Select("m.thread_id AS conversation_id, COUNT(*) AS count").
+		Joins("JOIN direct_message_threads t ON t.thread_id = m.thread_id").
+		Joins(`LEFT JOIN messages seen ON seen.message_id = CASE WHEN t.user1_id = ?
+			THEN t.user1_last_seen_message_id ELSE t.user2_last_seen_message_id END`, userID).
+		Where("t.user1_id = ? OR t.user2_id = ?", userID, userID).
+		Where("m.deleted_at IS NULL AND NOT (m.sender_id <=> ?)", userID).
+		Where(fmt.Sprintf(unreadAfterMarker, "TRUE")).
+		Group("m.thread_id")
+	return scanCounts(query)
+}
+
+func scanCounts(query *gorm.DB) (map[string]int64, error) {
+	var rows []conversationCount
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mentionBatchSize bounds each insert when @everyone fans out to a large room
+const mentionBatchSize = 500
+
+type MessageMentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageMentionRepository() *MessageMentionRepository {
+	return &MessageMentionRepository{
+		db: db.GetDB(),
+	}
+}
+
+// CreateForMessage records a mention of each user in the room message
+func (r *MessageMentionRepository) CreateForMessage(message *models.Message, userIDs []string) error {
+	if len(userIDs) == 0 || message.RoomID == nil {
+		return nil
+	}
+
+	mentions := make([]*models.MessageMention, 0, len(userIDs))
+	for _, userID := range userIDs {
+		mentions = append(mentions, &models.MessageMention{
+			MessageID: message.MessageID,
+			UserID:    userID,
+			RoomID:    *message.RoomID,
+			CreatedAt: message.CreatedAt,
+		})
+	}
+
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(mentions, mentionBatchSize).Error
+}
+
+// GetRecentForUser returns up to limit live messages mentioning the user in rooms they can still read,
+// newest first and older than the cursor. A nil cursor starts from the newest mention.
+func (r *MessageMentionRepository) GetRecentForUser(userID string, cursor *MessageCursor, limit int) ([]*models.Message, error) {
+	var messages []*models.Message
+	tx := r.db.Table("message_mentions mm").
+		Select("m.*").
+		Joins("JOIN messages m ON m.message_id = mm.message_id").
+		Where("mm.user_id = ? AND m.deleted_at IS NULL", userID).
+		Where("mm.room_id IN "+readableRoomIDs, userID, userID, time.Now())
+
+	// A mention's created_at is its message's, so message cursors walk the (user_id, created_at) index
+	if cursor != nil {
+		tx = tx.Where("mm.created_at < ? OR (mm.created_at = ? AND mm.message_id < ?)",
+			cursor.CreatedAt, cursor.CreatedAt, cursor.MessageID)
+	}
+
+	err := tx.Order("mm.created_at DESC").
+		Order("mm.message_id DESC").
+		Limit(limit).
+		Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
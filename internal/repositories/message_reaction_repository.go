@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MessageReactionRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageReactionRepository() *MessageReactionRepository {
+	return &MessageReactionRepository{
+		db: db.GetDB(),
+	}
+}
+
+// Add records the reaction; reacting twice with the same emoji is a no-op
+func (r *MessageReactionRepository) Add(reaction *models.MessageReaction) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error
+}
+
+// Remove deletes the user's reaction, reporting whether there was one
+func (r *MessageReactionRepository) Remove(messageID, userID, emoji string) (bool, error) {
+	result := r.db.Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Delete(&models.MessageReaction{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *MessageReactionRepository) CountForEmoji(messageID, emoji string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.MessageReaction{}).
+		Where("message_id = ? AND emoji = ?", messageID, emoji).
+		Count(&count).Error
+	return count, err
+}
+
+// GetSummaries aggregates the reactions on the given messages, keyed by message ID,
+// flagging the emojis the user reacted with. Emojis are ordered by first use.
+func (r *MessageReactionRepository) GetSummaries(messageIDs []string, userID string) (map[string][]*models.ReactionSummary, error) {
+	summaries := make(map[string][]*models.ReactionSummary, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return summaries, nil
+	}
+
+	var rows []*models.ReactionSummary
+	err := r.db.Model(&models.MessageReaction{}).
+		Select("message_id, emoji, COUNT(*) AS count, MAX(user_id = ?) AS reacted", userID).
+		Where("message_id IN ?", messageIDs).
+		Group("message_id, emoji").
+		Order("MIN(created_at) ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		summaries[row.MessageID] = append(summaries[row.MessageID], row)
+	}
+	return summaries, nil
+}
@@ -4,8 +4,11 @@ import (
 	"converse/internal/db"
 	"converse/internal/models"
 	"errors"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MessageRepository struct {
@@ -37,41 +40,452 @@ func (m *MessageRepository) StoreMessage(message *models.Message) error {
 		message.ContentType = "text" // Default to text if not specified
 	}
 
-	// Create the message in database
-	if err := m.db.Create(message).Error; err != nil {
-		return err
+	// Create the message and bump its conversation's activity together
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		return touchLastMessageAt(tx, message)
+	})
+}
+
+// touchLastMessageAt records the message as the latest activity of its room or DM thread.
+// It only moves forward, so a message committed after a newer one cannot pull it back.
+// UpdateColumn keeps updated_at for changes to the conversation itself.
+func touchLastMessageAt(tx *gorm.DB, message *models.Message) error {
+	if message.RoomID != nil {
+		return tx.Model(&models.Room{}).
+			Where("room_id = ?", *message.RoomID).
+			Where("last_message_at IS NULL OR last_message_at < ?", message.CreatedAt).
+			UpdateColumn("last_message_at", message.CreatedAt).Error
 	}
+	return tx.Model(&models.DirectMessageThread{}).
+		Where("thread_id = ?", *message.ThreadID).
+		Where("last_message_at IS NULL OR last_message_at < ?", message.CreatedAt).
+		UpdateColumn("last_message_at", message.CreatedAt).Error
+}
 
-	return nil
+// MessageCursor is a position in a conversation's history, ordered by (created_at, message_id)
+type MessageCursor struct {
+	CreatedAt time.Time
+	MessageID string
+}
+
+// PageDirection selects which side of a cursor to read
+type PageDirection int
+
+const (
+	PageOlder PageDirection = iota
+	PageNewer
+)
+
+// GetMessagesByRoomID retrieves up to limit messages from a room's main timeline on one side of the cursor,
+// newest first. A nil cursor starts from the newest message. Thread replies only appear if they were
+// also sent to the room. Deleted messages are included as tombstones.
+func (m *MessageRepository) GetMessagesByRoomID(roomID string, cursor *MessageCursor, direction PageDirection, limit int) ([]*models.Message, error) {
+	return m.getMessagePage(m.db.Where(roomTimelineScope, roomID), cursor, direction, limit)
+}
+
+// GetMessagesByThreadID retrieves up to limit thread messages on one side of the cursor, newest first, including tombstones
+func (m *MessageRepository) GetMessagesByThreadID(threadID string, cursor *MessageCursor, direction PageDirection, limit int) ([]*models.Message, error) {
+	return m.getMessagePage(m.db.Where(dmThreadScope, threadID), cursor, direction, limit)
+}
+
+// GetThreadReplies retrieves up to limit replies in an in-room thread on one side of the cursor, newest first
+func (m *MessageRepository) GetThreadReplies(parentMessageID string, cursor *MessageCursor, direction PageDirection, limit int) ([]*models.Message, error) {
+	return m.getMessagePage(m.db.Where(threadRepliesScope, parentMessageID), cursor, direction, limit)
 }
 
-// GetMessagesByRoomID retrieves messages for a specific room with pagination
-func (m *MessageRepository) GetMessagesByRoomID(roomID string, limit int, offset int) ([]*models.Message, error) {
+// The conditions selecting each kind of conversation, taking its ID
+const (
+	roomTimelineScope  = "room_id = ? AND (parent_message_id IS NULL OR also_sent_to_room = true)"
+	dmThreadScope      = "thread_id = ?"
+	threadRepliesScope = "parent_message_id = ?"
+)
+
+// conversationScope selects the conversation a message is shown in: its in-room thread for
+// replies, otherwise its room's main timeline or its DM thread
+func conversationScope(message *models.Message) (string, string, bool) {
+	switch {
+	case message.ParentMessageID != nil:
+		return threadRepliesScope, *message.ParentMessageID, true
+	case message.RoomID != nil:
+		return roomTimelineScope, *message.RoomID, true
+	case message.ThreadID != nil:
+		return dmThreadScope, *message.ThreadID, true
+	}
+	return "", "", false
+}
+
+// searchContextRow is a message next to the search hit HitID, on its newer side when Newer is set
+type searchContextRow struct {
+	models.Message `gorm:"embedded"`
+	HitID          string
+	Newer          bool
+}
+
+// GetSearchContexts loads up to size messages on each side of every hit within the hit's conversation,
+// in a single query of one UNION ALL branch per hit and side. The returned maps are keyed by the hit's
+// message ID and hold the older and newer neighbours, each newest first, including tombstones.
+func (m *MessageRepository) GetSearchContexts(hits []*models.Message, size int) (map[string][]*models.Message, map[string][]*models.Message, error) {
+	before := make(map[string][]*models.Message, len(hits))
+	after := make(map[string][]*models.Message, len(hits))
+
+	var branches []string
+	var args []any
+	for _, hit := range hits {
+		scope, conversationID, ok := conversationScope(hit)
+		if !ok {
+			continue
+		}
+		for _, newer := range []bool{false, true} {
+			comparison, order := "<", "DESC"
+			if newer {
+				comparison, order = ">", "ASC"
+			}
+			branches = append(branches, "(SELECT messages.*, ? AS hit_id, ? AS newer FROM messages WHERE "+scope+
+				" AND (created_at "+comparison+" ? OR (created_at = ? AND message_id "+comparison+" ?))"+
+				" ORDER BY created_at "+order+", message_id "+order+" LIMIT ?)")
+			args = append(args, hit.MessageID, newer, conversationID, hit.CreatedAt, hit.CreatedAt, hit.MessageID, size)
+		}
+	}
+	if len(branches) == 0 {
+		return before, after, nil
+	}
+
+	var rows []*searchContextRow
+	err := m.db.Raw(strings.Join(branches, " UNION ALL ")+" ORDER BY created_at DESC, message_id DESC", args...).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, row := range rows {
+		message := row.Message
+		if row.Newer {
+			after[row.HitID] = append(after[row.HitID], &message)
+		} else {
+			before[row.HitID] = append(before[row.HitID], &message)
+		}
+	}
+	return before, after, nil
+}
+
+// getMessagePage runs a keyset query that walks the (conversation, created_at) index selected by tx;
+// message_id, as the primary key, is implicitly part of that index and breaks ties
+func (m *MessageRepository) getMessagePage(tx *gorm.DB, cursor *MessageCursor, direction PageDirection, limit int) ([]*models.Message, error) {
 	var messages []*models.Message
 
-	err := m.db.Where("room_id = ? AND deleted_at IS NULL", roomID).
-		Order("created_at DESC").
+	comparison, order := "<", "DESC"
+	if direction == PageNewer {
+		comparison, order = ">", "ASC"
+	}
+
+	if cursor != nil {
+		tx = tx.Where("created_at "+comparison+" ? OR (created_at = ? AND message_id "+comparison+" ?)",
+			cursor.CreatedAt, cursor.CreatedAt, cursor.MessageID)
+	}
+
+	err := tx.Order("created_at " + order).
+		Order("message_id " + order).
 		Limit(limit).
-		Offset(offset).
 		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Newer pages are read oldest first so the rows nearest the cursor win the limit
+	if direction == PageNewer {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
 
-	return messages, err
+	return messages, nil
 }
 
-// GetMessagesByThreadID retrieves messages for a specific thread with pagination
-func (m *MessageRepository) GetMessagesByThreadID(threadID string, limit int, offset int) ([]*models.Message, error) {
-	var messages []*models.Message
+// StoreThreadReply stores a reply in an in-room thread. In the same transaction it bumps the
+// parent's reply count and last reply time and makes the replier and the parent's author followers.
+func (m *MessageRepository) StoreThreadReply(message *models.Message, parent *models.Message) error {
+	if message.ContentType == "" {
+		message.ContentType = "text"
+	}
 
-	err := m.db.Where("thread_id = ? AND deleted_at IS NULL", threadID).
-		Order("created_at DESC").
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Message{}).
+			Where("message_id = ?", parent.MessageID).
+			Updates(map[string]any{
+				"reply_count":   gorm.Expr("reply_count + 1"),
+				"last_reply_at": message.CreatedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		// Replies only count as room activity when they also appear in the room's timeline
+		if message.AlsoSentToRoom {
+			if err := touchLastMessageAt(tx, message); err != nil {
+				return err
+			}
+		}
+
+		followers := []*models.ThreadFollower{{MessageID: parent.MessageID, UserID: *message.SenderID}}
+		if parent.SenderID != nil && *parent.SenderID != *message.SenderID {
+			followers = append(followers, &models.ThreadFollower{MessageID: parent.MessageID, UserID: *parent.SenderID})
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(followers).Error
+	})
+}
+
+func (m *MessageRepository) FindByID(messageID string) (*models.Message, error) {
+	var message models.Message
+	err := m.db.Where("message_id = ?", messageID).First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// EditMessage replaces the message content, keeping the previous version in the edit history.
+// The row is locked so concurrent edits each record the version they replaced.
+func (m *MessageRepository) EditMessage(message *models.Message, editorID, content string) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Message
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("message_id = ?", message.MessageID).
+			First(&current).Error; err != nil {
+			return err
+		}
+
+		editedAt := time.Now()
+		edit := &models.MessageEdit{
+			MessageID: current.MessageID,
+			Content:   current.Content,
+			EditedBy:  editorID,
+			EditedAt:  editedAt,
+		}
+		if err := tx.Create(edit).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&current).Updates(map[string]any{
+			"content":    content,
+			"updated_at": editedAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		*message = current
+		message.Content = content
+		message.UpdatedAt = &editedAt
+		return nil
+	})
+}
+
+// SoftDelete turns the message into a tombstone: its content, metadata, edit history and reactions are
+// discarded, any pin is removed and deleted_at is set, but the row stays in place in the conversation history
+func (m *MessageRepository) SoftDelete(message *models.Message) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("message_id = ?", message.MessageID).Delete(&models.MessageEdit{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("message_id = ?", message.MessageID).Delete(&models.MessageReaction{}).Error; err != nil {
+			return err
+		}
+
+		deletedAt := time.Now()
+		if err := tx.Model(&models.Message{}).
+			Where("message_id = ?", message.MessageID).
+			Updates(map[string]any{
+				"content":    "",
+				"metadata":   nil,
+				"deleted_at": deletedAt,
+				"pinned_at":  nil,
+				"pinned_by":  nil,
+			}).Error; err != nil {
+			return err
+		}
+
+		message.Content = ""
+		message.Metadata = nil
+		message.DeletedAt = &deletedAt
+		message.PinnedAt = nil
+		message.PinnedBy = nil
+		return nil
+	})
+}
+
+// GetEditHistory returns the prior versions of a message, oldest first
+func (m *MessageRepository) GetEditHistory(messageID string) ([]*models.MessageEdit, error) {
+	var edits []*models.MessageEdit
+	err := m.db.Where("message_id = ?", messageID).
+		Order("edited_at ASC").
+		Find(&edits).Error
+	if err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
+
+// MessageSearchFilter narrows a full-text search; empty fields are ignored
+type MessageSearchFilter struct {
+	SenderID    string
+	RoomID      string
+	ThreadID    string
+	ContentType string
+	From        *time.Time
+	To          *time.Time
+}
+
+// SearchCursor is a position in search results, ordered by (relevance, created_at, message_id)
+type SearchCursor struct {
+	Relevance float64
+	MessageCursor
+}
+
+// SearchMessages runs a boolean-mode full-text query over the messages the user can read:
+// rooms they belong to, public channels of their spaces they are not banned from and their DM threads.
+// Results are ordered by relevance, most recent first among equals, and start after the cursor
+// when one is given.
+func (m *MessageRepository) SearchMessages(userID, booleanQuery string, filter MessageSearchFilter, cursor *SearchCursor, limit int) ([]*models.MessageSearchHit, error) {
+	var hits []*models.MessageSearchHit
+
+	tx := m.db.Table("messages m").
+		Select("m.*, r.name AS room_name, MATCH(m.content) AGAINST (? IN BOOLEAN MODE) AS relevance", booleanQuery).
+		Joins("LEFT JOIN rooms r ON r.room_id = m.room_id").
+		Where("MATCH(m.content) AGAINST (? IN BOOLEAN MODE)", booleanQuery).
+		Where("m.deleted_at IS NULL").
+		Where(`(
+			m.room_id IN `+readableRoomIDs+`
+			OR m.thread_id IN (
+				SELECT t.thread_id FROM direct_message_threads t WHERE t.user1_id = ? OR t.user2_id = ?
+			)
+		)`, userID, userID, time.Now(), userID, userID)
+
+	if filter.SenderID != "" {
+		tx = tx.Where("m.sender_id = ?", filter.SenderID)
+	}
+	if filter.RoomID != "" {
+		tx = tx.Where("m.room_id = ?", filter.RoomID)
+	}
+	if filter.ThreadID != "" {
+		tx = tx.Where("m.thread_id = ?", filter.ThreadID)
+	}
+	if filter.ContentType != "" {
+		tx = tx.Where("m.content_type = ?", filter.ContentType)
+	}
+	if filter.From != nil {
+		tx = tx.Where("m.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		tx = tx.Where("m.created_at <= ?", *filter.To)
+	}
+
+	if cursor != nil {
+		tx = tx.Where(`MATCH(m.content) AGAINST (? IN BOOLEAN MODE) < ? OR (
+			MATCH(m.content) AGAINST (? IN BOOLEAN MODE) = ?
+			AND (m.created_at < ? OR (m.created_at = ? AND m.message_id < ?))
+		)`, booleanQuery, cursor.Relevance, booleanQuery, cursor.Relevance,
+			cursor.CreatedAt, cursor.CreatedAt, cursor.MessageID)
+	}
+
+	err := tx.Order("relevance DESC").
+		Order("m.created_at DESC").
+		Order("m.message_id DESC").
 		Limit(limit).
-		Offset(offset).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// SetPinned pins the message on behalf of pinnedBy, or unpins it when pinnedBy is nil
+func (m *MessageRepository) SetPinned(message *models.Message, pinnedBy *string) error {
+	var pinnedAt *time.Time
+	if pinnedBy != nil {
+		now := time.Now()
+		pinnedAt = &now
+	}
+
+	err := m.db.Model(&models.Message{}).
+		Where("message_id = ?", message.MessageID).
+		Updates(map[string]any{
+			"pinned_at": pinnedAt,
+			"pinned_by": pinnedBy,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	message.PinnedAt = pinnedAt
+	message.PinnedBy = pinnedBy
+	return nil
+}
+
+// GetPinnedByRoomID returns the room's pinned messages, most recently pinned first
+func (m *MessageRepository) GetPinnedByRoomID(roomID string) ([]*models.Message, error) {
+	return m.getPinned(m.db.Where("room_id = ?", roomID))
+}
+
+// GetPinnedByThreadID returns the DM thread's pinned messages, most recently pinned first
+func (m *MessageRepository) GetPinnedByThreadID(threadID string) ([]*models.Message, error) {
+	return m.getPinned(m.db.Where("thread_id = ?", threadID))
+}
+
+func (m *MessageRepository) getPinned(tx *gorm.DB) ([]*models.Message, error) {
+	var messages []*models.Message
+	err := tx.Where("pinned_at IS NOT NULL").
+		Order("pinned_at DESC").
 		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetReplyPreviews summarizes the given parent messages, keyed by message ID
+func (m *MessageRepository) GetReplyPreviews(messageIDs []string) (map[string]*models.ReplyPreview, error) {
+	previews := make(map[string]*models.ReplyPreview, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return previews, nil
+	}
+
+	var rows []struct {
+		models.Message `gorm:"embedded"`
+		SenderUsername *string
+	}
+	err := m.db.Table("messages m").
+		Select("m.*, u.username AS sender_username").
+		Joins("LEFT JOIN users u ON u.user_id = m.sender_id").
+		Where("m.message_id IN ?", messageIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
 
-	return messages, err
+	for i := range rows {
+		previews[rows[i].MessageID] = models.NewReplyPreview(&rows[i].Message, rows[i].SenderUsername)
+	}
+	return previews, nil
+}
+
+// FindLatestRoomMessageBySender returns the most recent message the user sent to the room
+func (m *MessageRepository) FindLatestRoomMessageBySender(roomID, senderID string) (*models.Message, error) {
+	var message models.Message
+	err := m.db.Where("room_id = ? AND sender_id = ?", roomID, senderID).
+		Order("created_at DESC").
+		First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
 }
 
 // DB returns the database connection for use by other components
 func (m *MessageRepository) DB() *gorm.DB {
 	return m.db
-}
\ No newline at end of file
+}
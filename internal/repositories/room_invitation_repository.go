@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+	"converse/pkg/errors"
+	stderrors "errors"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+type RoomInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomInvitationRepository() *RoomInvitationRepository {
+	return &RoomInvitationRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *RoomInvitationRepository) Create(invitation *models.RoomInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *RoomInvitationRepository) FindPending(roomID, inviteeID string) (*models.RoomInvitation, error) {
+	var invitation models.RoomInvitation
+	err := r.db.Where("room_id = ? AND invitee_id = ? AND status = ?", roomID, inviteeID, "pending").
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetPendingForUser returns the pending invitations addressed to the user along with their rooms
+func (r *RoomInvitationRepository) GetPendingForUser(userID string) ([]*models.RoomInvitationWithRoom, error) {
+	var invitations []*models.RoomInvitationWithRoom
+	err := r.db.Table("room_invitations ri").
+		Select(`ri.invitation_id, ri.room_id, ri.inviter_id, ri.invitee_id, ri.status, ri.created_at, ri.updated_at,
+			r.room_id as room_room_id, r.name as room_name, r.description as room_description,
+			r.is_private as room_is_private, r.room_type as room_room_type,
+			r.parent_space_id as room_parent_space_id, r.created_by as room_created_by,
+			r.created_at as room_created_at, r.updated_at as room_updated_at,
+			r.last_message_at as room_last_message_at`).
+		Joins("JOIN rooms r ON ri.room_id = r.room_id").
+		Where("ri.invitee_id = ? AND ri.status = ?", userID, "pending").
+		Order("ri.created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *RoomInvitationRepository) Decline(invitationID, userID string) error {
+	result := r.db.Model(&models.RoomInvitation{}).
+		Where("invitation_id = ? AND invitee_id = ? AND status = ?", invitationID, userID, "pending").
+		Update("status", "declined")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundError("Invitation not found")
+	}
+	return nil
+}
+
+// Accept marks the invitation as accepted and adds the invitee to the room in one transaction
+func (r *RoomInvitationRepository) Accept(invitationID, userID string) (*models.RoomInvitation, error) {
+	var invitation models.RoomInvitation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("invitation_id = ? AND invitee_id = ? AND status = ?", invitationID, userID, "pending").
+			First(&invitation).Error
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.NewNotFoundError("Invitation not found")
+			}
+			return err
+		}
+
+		banned, err := isBanned(tx, invitation.RoomID, userID)
+		if err != nil {
+			return err
+		}
+		if banned {
+			return errors.NewForbiddenError("You are banned from this room")
+		}
+
+		if err := tx.Model(&invitation).Update("status", "accepted").Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.RoomMember{}).
+			Where("room_id = ? AND user_id = ?", invitation.RoomID, userID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return &errors.AppError{
+				Code:    http.StatusConflict,
+				Message: "You are already a member of this room",
+			}
+		}
+
+		member := &models.RoomMember{
+			RoomID: invitation.RoomID,
+			UserID: userID,
+			Role:   models.RoleMember,
+		}
+		return tx.Create(member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
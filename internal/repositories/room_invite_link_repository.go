@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+	"converse/pkg/errors"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoomInviteLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomInviteLinkRepository() *RoomInviteLinkRepository {
+	return &RoomInviteLinkRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *RoomInviteLinkRepository) Create(link *models.RoomInviteLink) error {
+	return r.db.Create(link).Error
+}
+
+// GetActiveForRoom returns the room's links that are not revoked, expired or used up
+func (r *RoomInviteLinkRepository) GetActiveForRoom(roomID string) ([]*models.RoomInviteLink, error) {
+	var links []*models.RoomInviteLink
+	err := r.db.Where("room_id = ? AND revoked_at IS NULL", roomID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Where("max_uses IS NULL OR uses < max_uses").
+		Order("created_at DESC").
+		Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *RoomInviteLinkRepository) Revoke(roomID, inviteLinkID string) error {
+	result := r.db.Model(&models.RoomInviteLink{}).
+		Where("invite_link_id = ? AND room_id = ? AND revoked_at IS NULL", inviteLinkID, roomID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundError("Invite link not found")
+	}
+	return nil
+}
+
+// Redeem consumes one use of the link and adds the user to its room. The link row is
+// locked for the duration of the transaction so concurrent redemptions cannot exceed max_uses.
+func (r *RoomInviteLinkRepository) Redeem(code, userID string) (*models.RoomInviteLink, error) {
+	var link models.RoomInviteLink
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", code).
+			First(&link).Error
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.NewNotFoundError("Invite link not found")
+			}
+			return err
+		}
+
+		if !link.IsUsable(time.Now()) {
+			return &errors.AppError{
+				Code:    http.StatusGone,
+				Message: "Invite link is no longer valid",
+				Details: "The link has been revoked, has expired or has reached its usage limit",
+			}
+		}
+
+		banned, err := isBanned(tx, link.RoomID, userID)
+		if err != nil {
+			return err
+		}
+		if banned {
+			return errors.NewForbiddenError("You are banned from this room")
+		}
+
+		var existing int64
+		if err := tx.Model(&models.RoomMember{}).
+			Where("room_id = ? AND user_id = ?", link.RoomID, userID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errors.NewConflictError("You are already a member of this room")
+		}
+
+		member := &models.RoomMember{
+			RoomID: link.RoomID,
+			UserID: userID,
+			Role:   models.RoleMember,
+		}
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+
+		link.Uses++
+		return tx.Model(&link).Update("uses", gorm.Expr("uses + 1")).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
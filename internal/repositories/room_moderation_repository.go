@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+	"converse/pkg/errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RoomModerationRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomModerationRepository() *RoomModerationRepository {
+	return &RoomModerationRepository{
+		db: db.GetDB(),
+	}
+}
+
+// activeBans scopes a query to bans that have not expired yet
+func activeBans(tx *gorm.DB) *gorm.DB {
+	return tx.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+}
+
+// activeMutes scopes a query to mutes that have not expired yet
+func activeMutes(tx *gorm.DB) *gorm.DB {
+	return tx.Where("expires_at > ?", time.Now())
+}
+
+// isBanned reports whether the user currently has an active ban in the room
+func isBanned(tx *gorm.DB, roomID, userID string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.RoomBan{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Scopes(activeBans).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Ban replaces any previous ban for the user and removes them from the room
+func (r *RoomModerationRepository) Ban(ban *models.RoomBan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("room_id = ? AND user_id = ?", ban.RoomID, ban.UserID).
+			Delete(&models.RoomBan{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(ban).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("room_id = ? AND user_id = ?", ban.RoomID, ban.UserID).
+			Delete(&models.RoomMember{}).Error
+	})
+}
+
+func (r *RoomModerationRepository) Unban(roomID, userID string) error {
+	result := r.db.Where("room_id = ? AND user_id = ?", roomID, userID).
+		Scopes(activeBans).
+		Delete(&models.RoomBan{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundError("Ban not found")
+	}
+	return nil
+}
+
+func (r *RoomModerationRepository) IsBanned(roomID, userID string) (bool, error) {
+	return isBanned(r.db, roomID, userID)
+}
+
+func (r *RoomModerationRepository) GetActiveBans(roomID string) ([]*models.RoomBan, error) {
+	var bans []*models.RoomBan
+	err := r.db.Where("room_id = ?", roomID).
+		Scopes(activeBans).
+		Order("created_at DESC").
+		Find(&bans).Error
+	if err != nil {
+		return nil, err
+	}
+	return bans, nil
+}
+
+// Mute replaces any previous mute for the user in the room
+func (r *RoomModerationRepository) Mute(mute *models.RoomMute) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("room_id = ? AND user_id = ?", mute.RoomID, mute.UserID).
+			Delete(&models.RoomMute{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(mute).Error
+	})
+}
+
+func (r *RoomModerationRepository) Unmute(roomID, userID string) error {
+	result := r.db.Where("room_id = ? AND user_id = ?", roomID, userID).
+		Scopes(activeMutes).
+		Delete(&models.RoomMute{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundError("Mute not found")
+	}
+	return nil
+}
+
+// FindActiveMute returns the user's unexpired mute in the room, or gorm.ErrRecordNotFound
+func (r *RoomModerationRepository) FindActiveMute(roomID, userID string) (*models.RoomMute, error) {
+	var mute models.RoomMute
+	err := r.db.Where("room_id = ? AND user_id = ?", roomID, userID).
+		Scopes(activeMutes).
+		First(&mute).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
+
+func (r *RoomModerationRepository) GetActiveMutes(roomID string) ([]*models.RoomMute, error) {
+	var mutes []*models.RoomMute
+	err := r.db.Where("room_id = ?", roomID).
+		Scopes(activeMutes).
+		Order("created_at DESC").
+		Find(&mutes).Error
+	if err != nil {
+		return nil, err
+	}
+	return mutes, nil
+}
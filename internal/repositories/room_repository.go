@@ -0,0 +1,409 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoomRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomRepository() *RoomRepository {
+	return &RoomRepository{
+		db: db.GetDB(),
+	}
+}
+
+// Create inserts the room and registers its creator as the owner in a single transaction
+func (r *RoomRepository) Create(room *models.Room) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(room).Error; err != nil {
+			return err
+		}
+
+		owner := &models.RoomMember{
+			RoomID: room.RoomID,
+			UserID: room.CreatedBy,
+			Role:   models.RoleOwner,
+		}
+		return tx.Create(owner).Error
+	})
+}
+
+func (r *RoomRepository) FindByID(roomID string) (*models.Room, error) {
+	var room models.Room
+	err := r.db.Where("room_id = ?", roomID).First(&room).Error
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *RoomRepository) Update(roomID string, updates map[string]any) error {
+	return r.db.Model(&models.Room{}).
+		Where("room_id = ?", roomID).
+		Updates(updates).Error
+}
+
+// Delete permanently removes the room along with its messages, memberships and every other
+// record that belongs to it. Deleting a space also deletes its categories and channels.
+// Returns the storage keys of the deleted messages' attachments and thumbnails, which the
+// caller should remove from storage.
+func (r *RoomRepository) Delete(roomID string) ([]string, error) {
+	var attachmentKeys []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		roomIDs := []string{roomID}
+
+		var channelIDs []string
+		if err := tx.Model(&models.Room{}).
+			Where("parent_space_id = ?", roomID).
+			Pluck("room_id", &channelIDs).Error; err != nil {
+			return err
+		}
+		roomIDs = append(roomIDs, channelIDs...)
+
+		var attachments []*models.Message
+		if err := tx.Select("message_id, metadata").
+			Where("room_id IN ? AND content_type IN ?", roomIDs, []string{models.ContentTypeImageURL, models.ContentTypeFileURL}).
+			Find(&attachments).Error; err != nil {
+			return err
+		}
+		for _, message := range attachments {
+			attachmentKeys = append(attachmentKeys, message.AttachmentKeys()...)
+		}
+
+		roomMessageIDs := tx.Model(&models.Message{}).Select("message_id").Where("room_id IN ?", roomIDs)
+		for _, dependent := range []any{&models.MessageEdit{}, &models.MessageReaction{}, &models.ThreadFollower{}, &models.MessageMention{}} {
+			if err := tx.Where("message_id IN (?)", roomMessageIDs).Delete(dependent).Error; err != nil {
+				return err
+			}
+		}
+
+		dependents := []any{
+			&models.Message{},
+			&models.RoomInvitation{},
+			&models.RoomInviteLink{},
+			&models.RoomBan{},
+			&models.RoomMute{},
+			&models.RoomMember{},
+			&models.RoomReadMarker{},
+			&models.RoomRole{},
+			&models.ScheduledMessage{},
+		}
+		for _, dependent := range dependents {
+			if err := tx.Where("room_id IN ?", roomIDs).Delete(dependent).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("space_id = ?", roomID).Delete(&models.SpaceCategory{}).Error; err != nil {
+			return err
+		}
+
+		// Channels first so the space row is never referenced by a remaining room
+		if err := tx.Where("parent_space_id = ?", roomID).Delete(&models.Room{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("room_id = ?", roomID).Delete(&models.Room{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attachmentKeys, nil
+}
+
+// TransferOwnership makes the target the room's owner and steps the current owner down to admin
+func (r *RoomRepository) TransferOwnership(roomID, fromUserID, toUserID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RoomMember{}).
+			Where("room_id = ? AND user_id = ?", roomID, toUserID).
+			Update("role", models.RoleOwner).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.RoomMember{}).
+			Where("room_id = ? AND user_id = ?", roomID, fromUserID).
+			Update("role", models.RoleAdmin).Error
+	})
+}
+
+// GetRoomsForUser returns every room the user is a member of, ordered by name or, for
+// RoomSortActivity, most recent message first
+func (r *RoomRepository) GetRoomsForUser(userID, sort string) ([]*models.Room, error) {
+	var rooms []*models.Room
+	tx := r.db.Table("rooms r").
+		Select("r.*").
+		Joins("JOIN room_members rm ON rm.room_id = r.room_id").
+		Where("rm.user_id = ?", userID)
+
+	if sort == RoomSortActivity {
+		tx = tx.Order("r.last_message_at IS NULL").Order("r.last_message_at DESC")
+	}
+
+	err := tx.Order("r.name ASC").Find(&rooms).Error
+	if err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (r *RoomRepository) FindMember(roomID, userID string) (*models.RoomMember, error) {
+	var member models.RoomMember
+	err := r.db.Where("room_id = ? AND user_id = ?", roomID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// IsBannedFromChannel reports whether the user has an active ban in the channel or in the space
+// containing it, either of which keeps them out of a channel they would reach through the space
+func (r *RoomRepository) IsBannedFromChannel(channel *models.Room, userID string) (bool, error) {
+	roomIDs := []string{channel.RoomID}
+	if channel.ParentSpaceID != nil {
+		roomIDs = append(roomIDs, *channel.ParentSpaceID)
+	}
+
+	for _, roomID := range roomIDs {
+		banned, err := isBanned(r.db, roomID, userID)
+		if err != nil || banned {
+			return banned, err
+		}
+	}
+	return false, nil
+}
+
+// GetMemberPermissions resolves the member's effective permissions in the room. Members of a
+// space get access to its public channels through their space membership and space role,
+// unless they are banned from the channel or the space.
+// Returns gorm.ErrRecordNotFound when the user is not a member.
+func (r *RoomRepository) GetMemberPermissions(roomID, userID string) (*models.RoomMember, models.PermissionSet, error) {
+	room, err := r.FindByID(roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	member, err := r.FindMember(roomID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) && room.ParentSpaceID != nil && !room.IsPrivate {
+		// Banning removes the channel membership, so the ban must also stop the space membership standing in for it
+		banned, err := r.IsBannedFromChannel(room, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if banned {
+			return nil, nil, gorm.ErrRecordNotFound
+		}
+
+		room, err = r.FindByID(*room.ParentSpaceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		member, err = r.FindMember(room.RoomID, userID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var customRole *models.RoomRole
+	if member.RoleID != nil {
+		var role models.RoomRole
+		err := r.db.Where("role_id = ? AND room_id = ?", *member.RoleID, member.RoomID).First(&role).Error
+		if err == nil {
+			customRole = &role
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+	}
+
+	return member, models.EffectivePermissions(room, member, customRole), nil
+}
+
+func (r *RoomRepository) AddMember(member *models.RoomMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *RoomRepository) RemoveMember(roomID, userID string) error {
+	return r.db.Where("room_id = ? AND user_id = ?", roomID, userID).
+		Delete(&models.RoomMember{}).Error
+}
+
+func (r *RoomRepository) UpdateMemberRole(roomID, userID, role string) error {
+	return r.db.Model(&models.RoomMember{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Update("role", role).Error
+}
+
+// GetMembers returns the members of a room along with their public user info
+func (r *RoomRepository) GetMembers(roomID string) ([]*models.RoomMemberWithUser, error) {
+	var members []*models.RoomMemberWithUser
+	err := r.db.Table("room_members rm").
+		Select(`rm.room_member_id, rm.room_id, rm.user_id, rm.role, rm.role_id, rm.joined_at,
+			u.user_id as user_user_id, u.username as user_username, u.email as user_email,
+			u.display_name as user_display_name, u.avatar_url as user_avatar_url,
+			u.status as user_status, u.last_active_at as user_last_active_at,
+			u.created_at as user_created_at, u.updated_at as user_updated_at`).
+		Joins("JOIN users u ON rm.user_id = u.user_id").
+		Where("rm.room_id = ? AND u.deleted_at IS NULL", roomID).
+		Order("rm.joined_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// behindMarkerCondition matches rows whose read marker column is unset or points at a message
+// older than the given one, so markers only ever move forward. Takes the message's created_at
+// twice and its ID as arguments.
+func behindMarkerCondition(column string) string {
+	return fmt.Sprintf(`(%[1]s IS NULL OR %[1]s = '' OR NOT EXISTS (
+		SELECT 1 FROM messages seen WHERE seen.message_id = %[1]s
+		AND (seen.created_at > ? OR (seen.created_at = ? AND seen.message_id >= ?))
+	))`, column)
+}
+
+// AdvanceLastSeen moves the member's read marker to the message unless it already points at
+// the same or a newer one. Reports whether the marker moved.
+func (r *RoomRepository) AdvanceLastSeen(roomID, userID string, message *models.Message) (bool, error) {
+	result := r.db.Model(&models.RoomMember{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Where(behindMarkerCondition("last_seen_message_id"), message.CreatedAt, message.CreatedAt, message.MessageID).
+		Update("last_seen_message_id", message.MessageID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// AdvanceSpaceMemberLastSeen does what AdvanceLastSeen does for a space member who reaches a
+// public channel through the space and so has no member row in it
+func (r *RoomRepository) AdvanceSpaceMemberLastSeen(roomID, userID string, message *models.Message) (bool, error) {
+	marker := &models.RoomReadMarker{RoomID: roomID, UserID: userID}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(marker).Error; err != nil {
+		return false, err
+	}
+
+	result := r.db.Model(&models.RoomReadMarker{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Where(behindMarkerCondition("last_seen_message_id"), message.CreatedAt, message.CreatedAt, message.MessageID).
+		Update("last_seen_message_id", message.MessageID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetReadMarkers returns the read markers of the room's members who have read anything,
+// including space members reading a public channel through the space
+func (r *RoomRepository) GetReadMarkers(roomID string) ([]*models.ReadMarker, error) {
+	markers := []*models.ReadMarker{}
+	err := r.db.Raw(`
+		SELECT rm.user_id, rm.last_seen_message_id FROM room_members rm
+		WHERE rm.room_id = ? AND rm.last_seen_message_id IS NOT NULL AND rm.last_seen_message_id <> ''
+		UNION ALL
+		SELECT mk.user_id, mk.last_seen_message_id FROM room_read_markers mk
+		JOIN rooms c ON c.room_id = mk.room_id
+		JOIN room_members sm ON sm.room_id = c.parent_space_id AND sm.user_id = mk.user_id
+		WHERE mk.room_id = ? AND mk.last_seen_message_id IS NOT NULL AND mk.last_seen_message_id <> ''
+		AND NOT EXISTS (SELECT 1 FROM room_members rm WHERE rm.room_id = mk.room_id AND rm.user_id = mk.user_id)
+	`, roomID, roomID).Scan(&markers).Error
+	if err != nil {
+		return nil, err
+	}
+	return markers, nil
+}
+
+// spaceMemberBannedFromChannel holds when the space member sm has an active ban in the public channel c.
+// Every query reaching channels through a space membership excludes it. It takes the current time.
+const spaceMemberBannedFromChannel = `EXISTS (
+	SELECT 1 FROM room_bans b WHERE b.room_id = c.room_id AND b.user_id = sm.user_id
+	AND (b.expires_at IS NULL OR b.expires_at > ?)
+)`
+
+// readableRoomIDs is a subquery of the rooms whose messages the user can read: their direct memberships
+// and the public channels of their spaces they are not banned from. It takes the user ID twice and the
+// current time.
+const readableRoomIDs = `(
+	SELECT rm.room_id FROM room_members rm WHERE rm.user_id = ?
+	UNION
+	SELECT c.room_id FROM rooms c
+	JOIN room_members sm ON sm.room_id = c.parent_space_id AND sm.user_id = ?
+	WHERE c.is_private = false AND NOT ` + spaceMemberBannedFromChannel + `
+)`
+
+// GetAudienceUserIDs returns everyone who should receive a room's events: its direct members
+// and, for public channels, the members of the parent space who are not banned from the channel
+func (r *RoomRepository) GetAudienceUserIDs(roomID string) ([]string, error) {
+	var userIDs []string
+	err := r.db.Raw(`
+		SELECT rm.user_id FROM room_members rm WHERE rm.room_id = ?
+		UNION
+		SELECT sm.user_id FROM rooms c
+		JOIN room_members sm ON sm.room_id = c.parent_space_id
+		WHERE c.room_id = ? AND c.is_private = false
+		AND NOT `+spaceMemberBannedFromChannel, roomID, roomID, time.Now()).Scan(&userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+func (r *RoomRepository) CountMembersWithRole(roomID, role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RoomMember{}).
+		Where("room_id = ? AND role = ?", roomID, role).
+		Count(&count).Error
+	return count, err
+}
+
+// Sort orders supported by SearchPublicRooms; GetRoomsForUser supports name and activity
+const (
+	RoomSortName     = "name"
+	RoomSortMembers  = "members"
+	RoomSortActivity = "activity"
+)
+
+// SearchPublicRooms lists non-private rooms and spaces (but not channels inside spaces) whose name or description matches the query,
+// along with their member count and whether the user has joined them
+func (r *RoomRepository) SearchPublicRooms(userID, query, sort string, limit, offset int) ([]*models.RoomDirectoryEntry, error) {
+	var entries []*models.RoomDirectoryEntry
+
+	tx := r.db.Table("rooms r").
+		Select(`r.*,
+			(SELECT COUNT(*) FROM room_members rm WHERE rm.room_id = r.room_id) AS member_count,
+			EXISTS(SELECT 1 FROM room_members rm WHERE rm.room_id = r.room_id AND rm.user_id = ?) AS joined`, userID).
+		Where("r.is_private = ? AND r.parent_space_id IS NULL", false)
+
+	if query != "" {
+		pattern := "%" + escapeLike(query) + "%"
+		tx = tx.Where("r.name LIKE ? OR r.description LIKE ?", pattern, pattern)
+	}
+
+	switch sort {
+	case RoomSortMembers:
+		tx = tx.Order("member_count DESC").Order("r.name ASC")
+	case RoomSortActivity:
+		tx = tx.Order("r.last_message_at IS NULL").Order("r.last_message_at DESC").Order("r.name ASC")
+	default:
+		tx = tx.Order("r.name ASC")
+	}
+
+	err := tx.Limit(limit).Offset(offset).Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// escapeLike escapes the LIKE wildcards in user supplied search terms
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RoomRoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomRoleRepository() *RoomRoleRepository {
+	return &RoomRoleRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *RoomRoleRepository) Create(role *models.RoomRole) error {
+	return r.db.Create(role).Error
+}
+
+func (r *RoomRoleRepository) FindByID(roomID, roleID string) (*models.RoomRole, error) {
+	var role models.RoomRole
+	err := r.db.Where("role_id = ? AND room_id = ?", roleID, roomID).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoomRoleRepository) GetForRoom(roomID string) ([]*models.RoomRole, error) {
+	var roles []*models.RoomRole
+	err := r.db.Where("room_id = ?", roomID).
+		Order("name ASC").
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *RoomRoleRepository) Update(role *models.RoomRole) error {
+	return r.db.Model(role).
+		Select("name", "permissions").
+		Updates(role).Error
+}
+
+// Delete removes the role and unassigns it from every member that held it
+func (r *RoomRoleRepository) Delete(roomID, roleID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RoomMember{}).
+			Where("room_id = ? AND role_id = ?", roomID, roleID).
+			Update("role_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("role_id = ? AND room_id = ?", roleID, roomID).
+			Delete(&models.RoomRole{}).Error
+	})
+}
+
+// AssignToMember sets or clears (nil roleID) the custom role of a room member
+func (r *RoomRoleRepository) AssignToMember(roomID, userID string, roleID *string) error {
+	return r.db.Model(&models.RoomMember{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Update("role_id", roleID).Error
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ScheduledMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduledMessageRepository() *ScheduledMessageRepository {
+	return &ScheduledMessageRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *ScheduledMessageRepository) Create(message *models.ScheduledMessage) error {
+	return r.db.Create(message).Error
+}
+
+func (r *ScheduledMessageRepository) FindByID(scheduledMessageID string) (*models.ScheduledMessage, error) {
+	var message models.ScheduledMessage
+	if err := r.db.Where("scheduled_message_id = ?", scheduledMessageID).First(&message).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetForSender returns the sender's undelivered scheduled messages, soonest first, optionally
+// limited to one room or DM thread
+func (r *ScheduledMessageRepository) GetForSender(senderID, roomID, threadID string) ([]*models.ScheduledMessage, error) {
+	query := r.db.Where("sender_id = ?", senderID)
+	if roomID != "" {
+		query = query.Where("room_id = ?", roomID)
+	}
+	if threadID != "" {
+		query = query.Where("thread_id = ?", threadID)
+	}
+
+	var messages []*models.ScheduledMessage
+	if err := query.Order("send_at ASC").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// UpdateUnclaimed applies the updates unless the dispatcher has already claimed the message.
+// It reports whether the message was updated.
+func (r *ScheduledMessageRepository) UpdateUnclaimed(scheduledMessageID string, updates map[string]any) (bool, error) {
+	result := r.db.Model(&models.ScheduledMessage{}).
+		Where("scheduled_message_id = ? AND status <> ?", scheduledMessageID, models.ScheduledStatusSending).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteUnclaimed cancels the message unless the dispatcher has already claimed it.
+// It reports whether the message was deleted.
+func (r *ScheduledMessageRepository) DeleteUnclaimed(scheduledMessageID string) (bool, error) {
+	result := r.db.Where("scheduled_message_id = ? AND status <> ?", scheduledMessageID, models.ScheduledStatusSending).
+		Delete(&models.ScheduledMessage{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindDue returns up to limit pending messages whose send time has passed, oldest first
+func (r *ScheduledMessageRepository) FindDue(now time.Time, limit int) ([]*models.ScheduledMessage, error) {
+	var messages []*models.ScheduledMessage
+	err := r.db.Where("status = ? AND send_at <= ?", models.ScheduledStatusPending, now).
+		Order("send_at ASC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// FindClaimed returns the messages left mid-send, such as by a server that stopped while sending them
+func (r *ScheduledMessageRepository) FindClaimed() ([]*models.ScheduledMessage, error) {
+	var messages []*models.ScheduledMessage
+	if err := r.db.Where("status = ?", models.ScheduledStatusSending).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Claim moves a pending message that is still due at now to sending, so that only one dispatcher
+// sends it, and returns the claimed row as it stands after the claim. It returns nil if the
+// message was cancelled, rescheduled or claimed by another dispatcher in the meantime.
+func (r *ScheduledMessageRepository) Claim(scheduledMessageID string, now time.Time) (*models.ScheduledMessage, error) {
+	result := r.db.Model(&models.ScheduledMessage{}).
+		Where("scheduled_message_id = ? AND status = ? AND send_at <= ?", scheduledMessageID, models.ScheduledStatusPending, now).
+		Update("status", models.ScheduledStatusSending)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	// Edits are refused while a message is being sent, so this is exactly what will go out
+	return r.FindByID(scheduledMessageID)
+}
+
+// Release returns a claimed message to pending, to be sent at sendAt
+func (r *ScheduledMessageRepository) Release(scheduledMessageID string, sendAt time.Time) error {
+	return r.db.Model(&models.ScheduledMessage{}).
+		Where("scheduled_message_id = ?", scheduledMessageID).
+		Updates(map[string]any{"status": models.ScheduledStatusPending, "send_at": sendAt}).Error
+}
+
+// MarkFailed records why a claimed message could not be sent
+func (r *ScheduledMessageRepository) MarkFailed(scheduledMessageID, reason string) error {
+	return r.db.Model(&models.ScheduledMessage{}).
+		Where("scheduled_message_id = ?", scheduledMessageID).
+		Updates(map[string]any{"status": models.ScheduledStatusFailed, "failure_reason": reason}).Error
+}
+
+// Delete removes a scheduled message once it has been sent
+func (r *ScheduledMessageRepository) Delete(scheduledMessageID string) error {
+	return r.db.Where("scheduled_message_id = ?", scheduledMessageID).Delete(&models.ScheduledMessage{}).Error
+}
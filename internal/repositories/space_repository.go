@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SpaceRepository struct {
+	db *gorm.DB
+}
+
+func NewSpaceRepository() *SpaceRepository {
+	return &SpaceRepository{
+		db: db.GetDB(),
+	}
+}
+
+func (r *SpaceRepository) CreateCategory(category *models.SpaceCategory) error {
+	return r.db.Create(category).Error
+}
+
+func (r *SpaceRepository) FindCategory(spaceID, categoryID string) (*models.SpaceCategory, error) {
+	var category models.SpaceCategory
+	err := r.db.Where("category_id = ? AND space_id = ?", categoryID, spaceID).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *SpaceRepository) GetCategories(spaceID string) ([]*models.SpaceCategory, error) {
+	var categories []*models.SpaceCategory
+	err := r.db.Where("space_id = ?", spaceID).
+		Order("position ASC").
+		Order("name ASC").
+		Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *SpaceRepository) UpdateCategory(category *models.SpaceCategory) error {
+	return r.db.Model(category).
+		Select("name", "position").
+		Updates(category).Error
+}
+
+// DeleteCategory removes the category, leaving its channels uncategorized
+func (r *SpaceRepository) DeleteCategory(spaceID, categoryID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Room{}).
+			Where("parent_space_id = ? AND category_id = ?", spaceID, categoryID).
+			Update("category_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("category_id = ? AND space_id = ?", categoryID, spaceID).
+			Delete(&models.SpaceCategory{}).Error
+	})
+}
+
+// GetVisibleChannels returns the space's public channels plus the private channels the user belongs to
+func (r *SpaceRepository) GetVisibleChannels(spaceID, userID string) ([]*models.Room, error) {
+	var channels []*models.Room
+	err := r.db.Table("rooms r").
+		Where("r.parent_space_id = ?", spaceID).
+		Where(`r.is_private = false OR EXISTS(
+			SELECT 1 FROM room_members rm WHERE rm.room_id = r.room_id AND rm.user_id = ?
+		)`, userID).
+		Order("r.position ASC").
+		Order("r.name ASC").
+		Find(&channels).Error
+	if err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// MoveChannel updates a channel's category and/or position within its space
+func (r *SpaceRepository) MoveChannel(spaceID, roomID string, updates map[string]any) error {
+	return r.db.Model(&models.Room{}).
+		Where("room_id = ? AND parent_space_id = ?", roomID, spaceID).
+		Updates(updates).Error
+}
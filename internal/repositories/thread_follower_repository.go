@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"converse/internal/db"
+	"converse/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ThreadFollowerRepository struct {
+	db *gorm.DB
+}
+
+func NewThreadFollowerRepository() *ThreadFollowerRepository {
+	return &ThreadFollowerRepository{
+		db: db.GetDB(),
+	}
+}
+
+// Follow subscribes the user to the thread; following twice is a no-op
+func (r *ThreadFollowerRepository) Follow(messageID, userID string) error {
+	follower := &models.ThreadFollower{MessageID: messageID, UserID: userID}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(follower).Error
+}
+
+func (r *ThreadFollowerRepository) Unfollow(messageID, userID string) error {
+	return r.db.Where("message_id = ? AND user_id = ?", messageID, userID).
+		Delete(&models.ThreadFollower{}).Error
+}
+
+func (r *ThreadFollowerRepository) IsFollowing(messageID, userID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ThreadFollower{}).
+		Where("message_id = ? AND user_id = ?", messageID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ThreadFollowerRepository) GetFollowerIDs(messageID string) ([]string, error) {
+	var userIDs []string
+	err := r.db.Model(&models.ThreadFollower{}).
+		Where("message_id = ?", messageID).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
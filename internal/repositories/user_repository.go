@@ -3,6 +3,7 @@ package repositories
 import (
 	"converse/internal/db"
 	"converse/internal/models"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -87,3 +88,46 @@ func (r *UserRepository) FindPublicUserByID(userID string) (*models.PublicUser,
 	return user.ToPublicUser(), nil
 }
 
+// FindPublicUsersByIDs returns the public info of the given users, keyed by user ID
+func (r *UserRepository) FindPublicUsersByIDs(userIDs []string) (map[string]*models.PublicUser, error) {
+	publicUsers := make(map[string]*models.PublicUser, len(userIDs))
+	if len(userIDs) == 0 {
+		return publicUsers, nil
+	}
+
+	var users []models.User
+	err := r.db.Select("user_id, username, email, display_name, avatar_url, status, last_active_at, created_at, updated_at, deleted_at").
+		Where("user_id IN ?", userIDs).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range users {
+		publicUsers[users[i].UserID] = users[i].ToPublicUser()
+	}
+	return publicUsers, nil
+}
+
+// FindIDsByUsernames maps the given usernames to user IDs, skipping unknown or deleted users.
+// Usernames match case-insensitively, as the column's collation compares them, so the result
+// is keyed by lowercased username.
+func (r *UserRepository) FindIDsByUsernames(usernames []string) (map[string]string, error) {
+	ids := make(map[string]string, len(usernames))
+	if len(usernames) == 0 {
+		return ids, nil
+	}
+
+	var users []models.User
+	err := r.db.Select("user_id, username").
+		Where("username IN ? AND deleted_at IS NULL", usernames).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		ids[strings.ToLower(user.Username)] = user.UserID
+	}
+	return ids, nil
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"converse/internal/media"
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/storage"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"converse/pkg/errors"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxAttachmentSize is the largest file that can be uploaded, in bytes
+const MaxAttachmentSize = 25 << 20
+
+// maxFileNameLength bounds the original file name kept in the metadata
+const maxFileNameLength = 255
+
+// AttachmentService handles uploading files as messages and serving them back to participants
+type AttachmentService struct {
+	roomAccess
+	messageRepo    *repositories.MessageRepository
+	dmRepo         *repositories.DirectMessageRepository
+	messageService *MessageService
+}
+
+// NewAttachmentService creates a new attachment service
+func NewAttachmentService(hub *websocket.Hub) *AttachmentService {
+	return &AttachmentService{
+		roomAccess:     roomAccess{roomRepo: repositories.NewRoomRepository()},
+		messageRepo:    repositories.NewMessageRepository(),
+		dmRepo:         repositories.NewDirectMessageRepository(),
+		messageService: NewMessageService(hub),
+	}
+}
+
+// Attachment is an uploaded file opened for download. The caller must close Content.
+type Attachment struct {
+	Content  io.ReadCloser
+	FileName string
+	MimeType string
+	Size     int64
+}
+
+// UploadAttachment stores the file and sends it to the room or DM thread as an image_url or
+// file_url message whose content is the download path. Size, MIME type and SHA-256 checksum
+// are recorded in the message metadata.
+func (s *AttachmentService) UploadAttachment(userID string, req types.UploadAttachmentRequest, file multipart.File, header *multipart.FileHeader) (*models.Message, error) {
+	if nonEmpty(req.RoomID) == nil && nonEmpty(req.ThreadID) == nil {
+		return nil, errors.NewBadRequestError("Missing conversation", "room_id or thread_id is required")
+	}
+
+	if header.Size == 0 {
+		return nil, errors.NewBadRequestError("File is empty", "")
+	}
+	if header.Size > MaxAttachmentSize {
+		return nil, errors.NewBadRequestError("File is too large", fmt.Sprintf("files can be at most %d MB", MaxAttachmentSize>>20))
+	}
+
+	// Make sure the user may post here before processing or storing anything
+	if err := s.messageService.checkCanSend(userID, nonEmpty(req.RoomID), nonEmpty(req.ThreadID)); err != nil {
+		return nil, sendError(err)
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := http.DetectContentType(data)
+
+	contentType := models.ContentTypeFileURL
+	messageID := uuid.New().String()
+	metadata := models.Metadata{}
+
+	// Images lose their EXIF metadata before anything is stored and get thumbnails for previews
+	var thumbnails []*media.Thumbnail
+	if strings.HasPrefix(mimeType, "image/") {
+		contentType = models.ContentTypeImageURL
+
+		processed, err := media.ProcessImage(data, mimeType)
+		if err != nil {
+			if stderrors.Is(err, media.ErrMalformedImage) {
+				return nil, errors.NewBadRequestError("Invalid image", "the image is corrupt or in an unsupported layout")
+			}
+			return nil, err
+		}
+		data = processed.Data
+		thumbnails = processed.Thumbnails
+
+		if processed.Width > 0 {
+			metadata[models.AttachmentWidth] = processed.Width
+			metadata[models.AttachmentHeight] = processed.Height
+			metadata[models.AttachmentBlurhash] = processed.Blurhash
+		}
+	}
+
+	checksum := sha256.Sum256(data)
+	object := storage.Object{
+		Key:         "attachments/" + messageID,
+		Size:        int64(len(data)),
+		ContentType: mimeType,
+		SHA256:      hex.EncodeToString(checksum[:]),
+	}
+
+	ctx := context.Background()
+	if err := storage.GetStorage().Put(ctx, object, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	storedKeys := []string{object.Key}
+
+	var thumbnailMetadata []any
+	for _, thumbnail := range thumbnails {
+		thumbnailObject := storage.Object{
+			Key:         fmt.Sprintf("thumbnails/%s/%d", messageID, thumbnail.Size),
+			Size:        int64(len(thumbnail.Data)),
+			ContentType: thumbnail.MimeType,
+		}
+		if err := storage.GetStorage().Put(ctx, thumbnailObject, bytes.NewReader(thumbnail.Data)); err != nil {
+			deleteObjects(storedKeys)
+			return nil, err
+		}
+		storedKeys = append(storedKeys, thumbnailObject.Key)
+
+		thumbnailMetadata = append(thumbnailMetadata, map[string]any{
+			models.ThumbnailMaxSize:     thumbnail.Size,
+			models.AttachmentSize:       thumbnailObject.Size,
+			models.AttachmentWidth:      thumbnail.Width,
+			models.AttachmentHeight:     thumbnail.Height,
+			models.AttachmentMimeType:   thumbnail.MimeType,
+			models.AttachmentStorageKey: thumbnailObject.Key,
+		})
+	}
+	if thumbnailMetadata != nil {
+		metadata[models.AttachmentThumbnails] = thumbnailMetadata
+	}
+
+	metadata[models.AttachmentStorageKey] = object.Key
+	metadata[models.AttachmentFileName] = cleanFileName(header.Filename)
+	metadata[models.AttachmentSize] = object.Size
+	metadata[models.AttachmentMimeType] = mimeType
+	metadata[models.AttachmentChecksum] = object.SHA256
+
+	incomingMsg := websocket.IncomingMessage{
+		Type:             websocket.MessageTypeNewMessage,
+		MessageID:        messageID,
+		RoomID:           nonEmpty(req.RoomID),
+		ThreadID:         nonEmpty(req.ThreadID),
+		Content:          "/api/v1/messages/" + messageID + "/attachment",
+		ContentType:      contentType,
+		ReplyToMessageID: nonEmpty(req.ReplyToMessageID),
+		ParentMessageID:  nonEmpty(req.ParentMessageID),
+		AlsoSendToRoom:   req.AlsoSendToRoom,
+	}
+
+	message, err := s.messageService.SendMessage(userID, incomingMsg, &metadata)
+	if err != nil {
+		deleteObjects(storedKeys)
+		return nil, sendError(err)
+	}
+
+	return message, nil
+}
+
+// GetAttachment opens a message's uploaded file for a participant of its room or DM thread
+func (s *AttachmentService) GetAttachment(messageID, userID string) (*Attachment, error) {
+	message, err := s.findAttachmentMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := *message.Metadata
+	fileName, _ := metadata[models.AttachmentFileName].(string)
+	return openAttachment(message.AttachmentKey(), fileName, metadata)
+}
+
+// GetThumbnail opens one of the thumbnails of an uploaded image; size is one of the sizes in its metadata
+func (s *AttachmentService) GetThumbnail(messageID, userID string, size int) (*Attachment, error) {
+	message, err := s.findAttachmentMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, thumbnail := range message.Thumbnails() {
+		// JSON numbers come back from the database as float64
+		if thumbnailSize, ok := thumbnail[models.ThumbnailMaxSize].(float64); ok && int(thumbnailSize) == size {
+			key, _ := thumbnail[models.AttachmentStorageKey].(string)
+			return openAttachment(key, fmt.Sprintf("thumbnail-%d", size), thumbnail)
+		}
+	}
+
+	return nil, errors.NewNotFoundError("Thumbnail not found")
+}
+
+// findAttachmentMessage loads a live message with an uploaded file, if the user takes part in its conversation
+func (s *AttachmentService) findAttachmentMessage(messageID, userID string) (*models.Message, error) {
+	message, err := s.messageRepo.FindByID(messageID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Message not found")
+		}
+		return nil, err
+	}
+
+	if message.RoomID != nil {
+		if _, _, err := s.memberPermissions(*message.RoomID, userID); err != nil {
+			return nil, err
+		}
+	} else if message.ThreadID != nil {
+		if err := s.checkParticipant(*message.ThreadID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if message.DeletedAt != nil || message.AttachmentKey() == "" {
+		return nil, errors.NewNotFoundError("Attachment not found")
+	}
+
+	return message, nil
+}
+
+// openAttachment opens a stored file, taking its MIME type and size from the metadata describing it
+func openAttachment(key, fileName string, metadata map[string]any) (*Attachment, error) {
+	if key == "" {
+		return nil, errors.NewNotFoundError("Attachment not found")
+	}
+
+	content, err := storage.GetStorage().Open(context.Background(), key)
+	if err != nil {
+		if stderrors.Is(err, storage.ErrNotFound) {
+			return nil, errors.NewNotFoundError("Attachment not found")
+		}
+		return nil, err
+	}
+
+	attachment := &Attachment{Content: content, FileName: fileName, Size: -1}
+	attachment.MimeType, _ = metadata[models.AttachmentMimeType].(string)
+	// JSON numbers come back from the database as float64
+	if size, ok := metadata[models.AttachmentSize].(float64); ok {
+		attachment.Size = int64(size)
+	}
+
+	return attachment, nil
+}
+
+// checkParticipant only allows the two participants of a DM thread
+func (s *AttachmentService) checkParticipant(threadID, userID string) error {
+	thread, err := s.dmRepo.FindThreadByID(threadID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFoundError("Message not found")
+		}
+		return err
+	}
+
+	if thread.User1ID != userID && thread.User2ID != userID {
+		return errors.NewNotFoundError("Message not found")
+	}
+	return nil
+}
+
+// deleteObjects removes stored files, such as those of an upload that was not sent or of deleted
+// messages. Failures are logged and leave the file behind.
+func deleteObjects(keys []string) {
+	for _, key := range keys {
+		if err := storage.GetStorage().Delete(context.Background(), key); err != nil {
+			log.Printf("Error deleting attachment %s: %v", key, err)
+		}
+	}
+}
+
+// cleanFileName drops any directory part of the client's file name and bounds its length
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return "file"
+	}
+	name = strings.ToValidUTF8(name, "")
+	for len(name) > maxFileNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// sendError turns a rejected send into an AppError, reporting slow mode as 429
+func sendError(err error) error {
+	var rejection *websocket.SendRejection
+	if !stderrors.As(err, &rejection) {
+		return err
+	}
+
+	if rejection.RetryAfter > 0 {
+		return &errors.AppError{
+			Code:    http.StatusTooManyRequests,
+			Message: rejection.Reason,
+			Details: fmt.Sprintf("retry after %d seconds", rejection.RetryAfter),
+		}
+	}
+	return errors.NewForbiddenError(rejection.Reason)
+}
+
+func nonEmpty(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
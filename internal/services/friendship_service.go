@@ -3,6 +3,7 @@ package services
 import (
 	"converse/internal/models"
 	"converse/internal/repositories"
+	"converse/pkg/errors"
 )
 
 type FriendshipService struct {
@@ -15,6 +16,15 @@ func NewFriendshipService() *FriendshipService {
 	}
 }
 
-func (s *FriendshipService) GetFriends(userID string) ([]*models.PublicUser, error) {
-	return s.friendshipRepo.GetFriends(userID)
+// GetFriends returns the user's friends, by username or by most recent conversation
+func (s *FriendshipService) GetFriends(userID, sort string) ([]*models.PublicUser, error) {
+	switch sort {
+	case "":
+		sort = repositories.FriendSortUsername
+	case repositories.FriendSortUsername, repositories.FriendSortActivity:
+	default:
+		return nil, errors.NewBadRequestError("Invalid sort order", "sort must be one of username or activity")
+	}
+
+	return s.friendshipRepo.GetFriends(userID, sort)
 }
\ No newline at end of file
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"sort"
+)
+
+// InboxService builds the list of the user's conversations with their unread state
+type InboxService struct {
+	dmRepo      *repositories.DirectMessageRepository
+	messageRepo *repositories.MessageRepository
+	userRepo    *repositories.UserRepository
+	inboxRepo   *repositories.InboxRepository
+}
+
+// NewInboxService creates a new inbox service
+func NewInboxService() *InboxService {
+	return &InboxService{
+		dmRepo:      repositories.NewDirectMessageRepository(),
+		messageRepo: repositories.NewMessageRepository(),
+		userRepo:    repositories.NewUserRepository(),
+		inboxRepo:   repositories.NewInboxRepository(),
+	}
+}
+
+// GetInbox returns every room and DM thread the user belongs to, including the public channels of
+// their spaces, most recently active first.
+// Unread counts start after the user's read marker; spaces hold no messages and are left out.
+func (s *InboxService) GetInbox(userID string) ([]*models.InboxEntry, error) {
+	roomEntries, err := s.getRoomEntries(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dmEntries, err := s.getDMEntries(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := append(roomEntries, dmEntries...)
+	if err := s.attachPreviews(entries); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastActivityAt.After(entries[j].LastActivityAt)
+	})
+
+	return entries, nil
+}
+
+func (s *InboxService) getRoomEntries(userID string) ([]*models.InboxEntry, error) {
+	rooms, err := s.inboxRepo.GetRooms(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roomIDs := make([]string, 0, len(rooms))
+	for _, room := range rooms {
+		roomIDs = append(roomIDs, room.RoomID)
+	}
+
+	latest, err := s.inboxRepo.GetLatestRoomMessages(roomIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	lastSeen, err := s.inboxRepo.GetRoomLastSeen(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.inboxRepo.CountUnreadRoomMessages(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.inboxRepo.CountUnreadRoomMentions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*models.InboxEntry, 0, len(rooms))
+	for _, room := range rooms {
+		entry := &models.InboxEntry{
+			Type:               models.InboxEntryRoom,
+			Room:               room,
+			LastActivityAt:     room.CreatedAt,
+			LastSeenMessageID:  lastSeen[room.RoomID],
+			UnreadCount:        unread[room.RoomID],
+			UnreadMentionCount: mentions[room.RoomID],
+		}
+		setLatestMessage(entry, latest[room.RoomID])
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (s *InboxService) getDMEntries(userID string) ([]*models.InboxEntry, error) {
+	threads, err := s.dmRepo.GetThreadsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	threadIDs := make([]string, 0, len(threads))
+	otherUserIDs := make([]string, 0, len(threads))
+	for _, thread := range threads {
+		threadIDs = append(threadIDs, thread.DirectMessageThreadID)
+		otherUserIDs = append(otherUserIDs, otherParticipant(thread, userID))
+	}
+
+	latest, err := s.inboxRepo.GetLatestThreadMessages(threadIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.inboxRepo.CountUnreadThreadMessages(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	otherUsers, err := s.userRepo.FindPublicUsersByIDs(otherUserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*models.InboxEntry, 0, len(threads))
+	for _, thread := range threads {
+		lastSeen := thread.User1LastSeenMessageID
+		if thread.User2ID == userID {
+			lastSeen = thread.User2LastSeenMessageID
+		}
+
+		entry := &models.InboxEntry{
+			Type:              models.InboxEntryDM,
+			ThreadID:          &thread.DirectMessageThreadID,
+			OtherUser:         otherUsers[otherParticipant(thread, userID)],
+			LastActivityAt:    thread.CreatedAt,
+			LastSeenMessageID: lastSeen,
+			UnreadCount:       unread[thread.DirectMessageThreadID],
+		}
+		setLatestMessage(entry, latest[thread.DirectMessageThreadID])
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// attachPreviews loads the last message preview of every entry in one query
+func (s *InboxService) attachPreviews(entries []*models.InboxEntry) error {
+	var ids []string
+	for _, entry := range entries {
+		if entry.LastMessage != nil {
+			ids = append(ids, entry.LastMessage.MessageID)
+		}
+	}
+
+	previews, err := s.messageRepo.GetReplyPreviews(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.LastMessage != nil {
+			entry.LastMessage = previews[entry.LastMessage.MessageID]
+		}
+	}
+	return nil
+}
+
+// setLatestMessage records the conversation's newest message, leaving a placeholder preview for attachPreviews
+func setLatestMessage(entry *models.InboxEntry, latest *repositories.LatestMessage) {
+	if latest == nil {
+		return
+	}
+	entry.LastMessage = &models.ReplyPreview{MessageID: latest.MessageID}
+	entry.LastActivityAt = latest.CreatedAt
+}
+
+func otherParticipant(thread *models.DirectMessageThread, userID string) string {
+	if thread.User1ID == userID {
+		return thread.User2ID
+	}
+	return thread.User1ID
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/websocket"
+	"log"
+)
+
+// membershipNotifier records membership changes in room history and announces them over the hub
+type membershipNotifier struct {
+	messageRepo *repositories.MessageRepository
+	userRepo    *repositories.UserRepository
+	hub         *websocket.Hub
+}
+
+func newMembershipNotifier(hub *websocket.Hub) membershipNotifier {
+	return membershipNotifier{
+		messageRepo: repositories.NewMessageRepository(),
+		userRepo:    repositories.NewUserRepository(),
+		hub:         hub,
+	}
+}
+
+// notify stores a system notification for a user joining or leaving a room and pushes the
+// matching user_joined / user_left event to the room and the affected user
+func (n membershipNotifier) notify(roomID, userID, actorID string, eventType websocket.WebSocketMessageType, reason string) {
+	username := userID
+	if user, err := n.userRepo.FindByID(userID); err == nil {
+		username = user.Username
+	}
+
+	metadata := models.Metadata{
+		"event":   string(eventType),
+		"user_id": userID,
+		"reason":  reason,
+	}
+	if actorID != "" && actorID != userID {
+		metadata["actor_id"] = actorID
+	}
+
+	message := &models.Message{
+		RoomID:      &roomID,
+		ContentType: "system_notification",
+		Content:     membershipChangeContent(username, reason),
+		Metadata:    &metadata,
+	}
+
+	if err := n.messageRepo.StoreMessage(message); err != nil {
+		log.Printf("Error storing membership notification for room %s: %v", roomID, err)
+		return
+	}
+
+	n.hub.BroadcastMembershipChange(message, eventType, userID)
+}
+
+// membershipChangeContent renders the history text for a membership change
+func membershipChangeContent(username, reason string) string {
+	switch reason {
+	case websocket.MembershipReasonAdded:
+		return username + " was added to the room"
+	case websocket.MembershipReasonInvite, websocket.MembershipReasonInviteLink:
+		return username + " joined the room by invitation"
+	case websocket.MembershipReasonLeave:
+		return username + " left the room"
+	case websocket.MembershipReasonKick:
+		return username + " was removed from the room"
+	case websocket.MembershipReasonBan:
+		return username + " was banned from the room"
+	default:
+		return username + " joined the room"
+	}
+}
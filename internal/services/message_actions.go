@@ -0,0 +1,412 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"converse/pkg/errors"
+	stderrors "errors"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// maxEmojiLength matches the width of the message_reactions.emoji column
+const maxEmojiLength = 64
+
+// HandleAction carries out a message action a client sent over the WebSocket
+func (s *MessageService) HandleAction(userID string, action websocket.IncomingMessage) error {
+	if action.Type != websocket.MessageTypeNewMessage && action.MessageID == "" {
+		return errors.NewBadRequestError("Message ID is required", "")
+	}
+
+	switch action.Type {
+	case websocket.MessageTypeNewMessage:
+		// Message IDs are assigned by the server; only trusted callers of SendMessage may choose one.
+		// Attachment and system messages likewise only come from the services that create them.
+		if action.ContentType != "" && action.ContentType != "text" {
+			return errors.NewBadRequestError("Unsupported content type", "Only text messages can be sent directly")
+		}
+		action.MessageID = ""
+		_, err := s.SendMessage(userID, action, nil)
+		return err
+	case websocket.MessageTypeEditMessage:
+		_, err := s.EditMessage(action.MessageID, userID, types.EditMessageRequest{Content: action.Content})
+		return err
+	case websocket.MessageTypeDeleteMessage:
+		return s.DeleteMessage(action.MessageID, userID)
+	case websocket.MessageTypeAddReaction:
+		return s.addReaction(action.MessageID, userID, action.Emoji)
+	case websocket.MessageTypeRemoveReaction:
+		return s.removeReaction(action.MessageID, userID, action.Emoji)
+	case websocket.MessageTypePinMessage:
+		_, err := s.PinMessage(action.MessageID, userID)
+		return err
+	case websocket.MessageTypeUnpinMessage:
+		_, err := s.UnpinMessage(action.MessageID, userID)
+		return err
+	case websocket.MessageTypeMarkRead:
+		return s.MarkRead(action.MessageID, userID)
+	default:
+		return errors.NewBadRequestError("Unknown message type", "")
+	}
+}
+
+// EditMessage replaces the content of one of the user's own text messages and broadcasts a
+// message_edited event to the room or thread
+func (s *MessageService) EditMessage(messageID, userID string, req types.EditMessageRequest) (*models.Message, error) {
+	if req.Content == "" {
+		return nil, errors.NewBadRequestError("Message content cannot be empty", "")
+	}
+
+	message, _, err := s.findModifiableMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.SenderID == nil || *message.SenderID != userID {
+		return nil, errors.NewForbiddenError("You can only edit your own messages")
+	}
+
+	// The content of attachment messages is the server-generated download path
+	if message.ContentType != "text" {
+		return nil, errors.NewBadRequestError("Only text messages can be edited", "")
+	}
+
+	// A muted user cannot change what the room sees any more than they can post to it
+	if message.RoomID != nil {
+		room, err := s.findRoom(*message.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		mute, err := s.findActiveMute(room, userID)
+		if err != nil {
+			return nil, err
+		}
+		if mute != nil {
+			return nil, errors.NewForbiddenError(mutedReason(mute))
+		}
+	}
+
+	if message.Content == req.Content {
+		return message, nil
+	}
+
+	if err := s.messageRepo.EditMessage(message, userID, req.Content); err != nil {
+		return nil, err
+	}
+
+	s.hub.BroadcastMessageEvent(websocket.MessageTypeMessageEdited, message, userID)
+	return message, nil
+}
+
+// DeleteMessage deletes a message for everyone, leaving a tombstone in history, and broadcasts
+// a message_deleted event. Senders may delete their own messages; in rooms, members with the
+// delete_messages permission may delete those of members they outrank.
+func (s *MessageService) DeleteMessage(messageID, userID string) error {
+	message, permissions, err := s.findModifiableMessage(messageID, userID)
+	if err != nil {
+		return err
+	}
+
+	isSender := message.SenderID != nil && *message.SenderID == userID
+	if !isSender {
+		if !permissions.Has(models.PermissionDeleteMessages) {
+			return errors.NewForbiddenError("You can only delete your own messages")
+		}
+		if err := s.checkOutranksSender(message, userID); err != nil {
+			return err
+		}
+	}
+
+	attachmentKeys := message.AttachmentKeys()
+	if err := s.messageRepo.SoftDelete(message); err != nil {
+		return err
+	}
+
+	// The tombstone no longer references the file or its thumbnails, so remove them from storage too
+	deleteObjects(attachmentKeys)
+
+	s.hub.BroadcastMessageEvent(websocket.MessageTypeMessageDeleted, message, userID)
+	return nil
+}
+
+// PinMessage pins a message to its room or DM thread and broadcasts a message_pinned event.
+// In rooms this requires the pin_messages permission; either participant may pin in a DM.
+func (s *MessageService) PinMessage(messageID, userID string) (*models.Message, error) {
+	message, err := s.findPinnableMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.PinnedAt != nil {
+		return nil, errors.NewConflictError("Message is already pinned")
+	}
+
+	if err := s.messageRepo.SetPinned(message, &userID); err != nil {
+		return nil, err
+	}
+
+	s.hub.BroadcastMessageEvent(websocket.MessageTypeMessagePinned, message, userID)
+	return message, nil
+}
+
+// UnpinMessage removes a message's pin and broadcasts a message_unpinned event
+func (s *MessageService) UnpinMessage(messageID, userID string) (*models.Message, error) {
+	message, err := s.findPinnableMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.PinnedAt == nil {
+		return nil, errors.NewConflictError("Message is not pinned")
+	}
+
+	if err := s.messageRepo.SetPinned(message, nil); err != nil {
+		return nil, err
+	}
+
+	s.hub.BroadcastMessageEvent(websocket.MessageTypeMessageUnpinned, message, userID)
+	return message, nil
+}
+
+// findPinnableMessage loads a message the user may pin or unpin
+func (s *MessageService) findPinnableMessage(messageID, userID string) (*models.Message, error) {
+	message, permissions, err := s.findModifiableMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.RoomID != nil && !permissions.Has(models.PermissionPinMessages) {
+		return nil, errors.NewForbiddenError("You do not have permission to pin messages in this room")
+	}
+
+	return message, nil
+}
+
+// AddReaction reacts to a message with an emoji and returns the message's updated reactions
+func (s *MessageService) AddReaction(messageID, userID string, req types.ReactionRequest) ([]*models.ReactionSummary, error) {
+	if err := s.addReaction(messageID, userID, req.Emoji); err != nil {
+		return nil, err
+	}
+	return s.getReactions(messageID, userID)
+}
+
+// RemoveReaction withdraws the user's emoji reaction and returns the message's updated reactions
+func (s *MessageService) RemoveReaction(messageID, userID, emoji string) ([]*models.ReactionSummary, error) {
+	if err := s.removeReaction(messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+	return s.getReactions(messageID, userID)
+}
+
+// addReaction records the user's emoji reaction and broadcasts a reaction_updated event
+func (s *MessageService) addReaction(messageID, userID, emoji string) error {
+	if err := validateEmoji(emoji); err != nil {
+		return err
+	}
+
+	message, _, err := s.findModifiableMessage(messageID, userID)
+	if err != nil {
+		return err
+	}
+
+	reaction := &models.MessageReaction{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+	}
+	if err := s.reactionRepo.Add(reaction); err != nil {
+		return err
+	}
+
+	s.broadcastReactionUpdate(message, userID, emoji, websocket.ReactionAdded)
+	return nil
+}
+
+// removeReaction withdraws the user's emoji reaction and broadcasts a reaction_updated event
+func (s *MessageService) removeReaction(messageID, userID, emoji string) error {
+	if err := validateEmoji(emoji); err != nil {
+		return err
+	}
+
+	message, _, err := s.findModifiableMessage(messageID, userID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.reactionRepo.Remove(messageID, userID, emoji)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errors.NewNotFoundError("Reaction not found")
+	}
+
+	s.broadcastReactionUpdate(message, userID, emoji, websocket.ReactionRemoved)
+	return nil
+}
+
+// broadcastReactionUpdate announces a changed reaction along with the new count for that emoji
+func (s *MessageService) broadcastReactionUpdate(message *models.Message, userID, emoji, action string) {
+	count, err := s.reactionRepo.CountForEmoji(message.MessageID, emoji)
+	if err != nil {
+		log.Printf("Error counting reactions on message %s: %v", message.MessageID, err)
+		return
+	}
+
+	s.hub.BroadcastReactionUpdate(message, userID, emoji, action, count)
+}
+
+func (s *MessageService) getReactions(messageID, userID string) ([]*models.ReactionSummary, error) {
+	reactions, err := s.reactionRepo.GetSummaries([]string{messageID}, userID)
+	if err != nil {
+		return nil, err
+	}
+	decorations := &messageDecorations{reactions: reactions}
+	return decorations.reactionsFor(messageID), nil
+}
+
+func validateEmoji(emoji string) error {
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxEmojiLength || strings.ContainsFunc(emoji, unicode.IsSpace) {
+		return errors.NewBadRequestError("Invalid emoji", "emoji must be a single non-empty token of at most 64 characters")
+	}
+	return nil
+}
+
+// MarkRead moves the user's read marker in the message's room or DM thread up to the message and
+// sends a read_receipt event to the other participants. Markers never move backwards, so marking
+// an older message is a no-op.
+func (s *MessageService) MarkRead(messageID, userID string) error {
+	message, err := s.messageRepo.FindByID(messageID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFoundError("Message not found")
+		}
+		return err
+	}
+
+	if message.ParentMessageID != nil && !message.AlsoSentToRoom {
+		return errors.NewBadRequestError("Thread replies cannot be marked as read", "Mark the latest room message instead")
+	}
+
+	var advanced bool
+	switch {
+	case message.RoomID != nil:
+		member, _, permErr := s.memberPermissions(*message.RoomID, userID)
+		if permErr != nil {
+			return permErr
+		}
+		if member.RoomID == *message.RoomID {
+			advanced, err = s.roomRepo.AdvanceLastSeen(*message.RoomID, userID, message)
+		} else {
+			// Reading a public channel through the space membership
+			advanced, err = s.roomRepo.AdvanceSpaceMemberLastSeen(*message.RoomID, userID, message)
+		}
+	case message.ThreadID != nil:
+		thread, findErr := s.dmRepo.FindThreadByID(*message.ThreadID)
+		if findErr != nil {
+			return findErr
+		}
+		if thread.User1ID != userID && thread.User2ID != userID {
+			return errors.NewNotFoundError("Message not found")
+		}
+		advanced, err = s.dmRepo.AdvanceLastSeen(thread, userID, message)
+	default:
+		return errors.NewNotFoundError("Message not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if advanced {
+		s.hub.BroadcastReadReceipt(userID, message)
+	}
+	return nil
+}
+
+// checkOutranksSender ensures a moderator deleting someone else's room message outranks its sender,
+// so moderators cannot remove the messages of admins or owners. Senders who have left the room or
+// deleted their account no longer hold a role and can always be moderated.
+func (s *MessageService) checkOutranksSender(message *models.Message, userID string) error {
+	if message.SenderID == nil {
+		return nil
+	}
+
+	actor, _, err := s.roomRepo.GetMemberPermissions(*message.RoomID, userID)
+	if err != nil {
+		return err
+	}
+
+	sender, _, err := s.roomRepo.GetMemberPermissions(*message.RoomID, *message.SenderID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	outranks, err := s.outranks(*message.RoomID, actor, sender)
+	if err != nil {
+		return err
+	}
+	if !outranks {
+		return errors.NewForbiddenError("You cannot delete messages from a member with an equal or higher role")
+	}
+	return nil
+}
+
+// findModifiableMessage loads a live message and ensures the user can still write to its room or thread.
+// For room messages it also returns the user's permissions in the room; DM threads have none.
+func (s *MessageService) findModifiableMessage(messageID, userID string) (*models.Message, models.PermissionSet, error) {
+	message, err := s.messageRepo.FindByID(messageID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.NewNotFoundError("Message not found")
+		}
+		return nil, nil, err
+	}
+
+	if message.DeletedAt != nil {
+		return nil, nil, errors.NewNotFoundError("Message not found")
+	}
+
+	var permissions models.PermissionSet
+
+	if message.RoomID != nil {
+		room, err := s.roomRepo.FindByID(*message.RoomID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		_, permissions, err = s.roomRepo.GetMemberPermissions(room.RoomID, userID)
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, errors.NewForbiddenError("You are not a member of this room")
+			}
+			return nil, nil, err
+		}
+
+		archived, err := s.isArchived(room)
+		if err != nil {
+			return nil, nil, err
+		}
+		if archived {
+			return nil, nil, errors.NewForbiddenError("This room is archived and is read-only")
+		}
+	}
+
+	if message.ThreadID != nil {
+		if err := s.checkThreadAccess(*message.ThreadID, userID); err != nil {
+			if _, ok := err.(*errors.AppError); ok {
+				return nil, nil, errors.NewNotFoundError("Message not found")
+			}
+			return nil, nil, err
+		}
+	}
+
+	return message, permissions, nil
+}
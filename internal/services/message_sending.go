@@ -0,0 +1,429 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/websocket"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SendMessage validates, stores and delivers a new message from the sender, echoing it back to them.
+// Server-generated metadata, such as attachment details, is stored alongside any mentions, and a
+// non-empty MessageID is used as the new message's ID.
+// Rejections meant for the sender are returned as *websocket.SendRejection.
+func (s *MessageService) SendMessage(senderID string, incomingMsg websocket.IncomingMessage, metadata *models.Metadata) (*models.Message, error) {
+	if incomingMsg.Content == "" {
+		return nil, &websocket.SendRejection{Reason: "Message content cannot be empty"}
+	}
+
+	if err := s.checkCanSend(senderID, incomingMsg.RoomID, incomingMsg.ThreadID); err != nil {
+		return nil, err
+	}
+
+	var parent *models.Message
+	if incomingMsg.ParentMessageID != nil {
+		var err error
+		if parent, err = s.findThreadParent(*incomingMsg.ParentMessageID, incomingMsg.RoomID); err != nil {
+			return nil, err
+		}
+	}
+
+	var replyTo *models.ReplyPreview
+	if incomingMsg.ReplyToMessageID != nil {
+		var err error
+		replyTo, err = s.findReplyTarget(*incomingMsg.ReplyToMessageID, incomingMsg.RoomID, incomingMsg.ThreadID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	message := &models.Message{
+		MessageID:        incomingMsg.MessageID,
+		RoomID:           incomingMsg.RoomID,
+		ThreadID:         incomingMsg.ThreadID,
+		SenderID:         &senderID,
+		Content:          incomingMsg.Content,
+		ContentType:      incomingMsg.ContentType,
+		Metadata:         metadata,
+		ReplyToMessageID: incomingMsg.ReplyToMessageID,
+	}
+
+	if message.ContentType == "" {
+		message.ContentType = "text"
+	}
+
+	// Resolve @mentions against the room before storing so they are saved in the metadata
+	mentioned, err := s.resolveMentions(message)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent != nil {
+		message.ParentMessageID = &parent.MessageID
+		message.AlsoSentToRoom = incomingMsg.AlsoSendToRoom
+		err = s.messageRepo.StoreThreadReply(message, parent)
+	} else {
+		err = s.messageRepo.StoreMessage(message)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storing message: %w", err)
+	}
+
+	s.hub.DeliverMessage(message, replyTo)
+
+	if parent != nil {
+		if updated, err := s.messageRepo.FindByID(parent.MessageID); err != nil {
+			log.Printf("Error reloading thread parent %s: %v", parent.MessageID, err)
+		} else {
+			s.hub.BroadcastThreadUpdate(updated)
+		}
+	}
+
+	s.notifyMentions(message, mentioned)
+	return message, nil
+}
+
+// checkCanSend verifies that the user may post to the room or DM thread the message targets
+func (s *MessageService) checkCanSend(userID string, roomID, threadID *string) error {
+	if roomID != nil {
+		room, err := s.roomRepo.FindByID(*roomID)
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return &websocket.SendRejection{Reason: "Room not found"}
+			}
+			return err
+		}
+
+		if room.IsSpace() {
+			return &websocket.SendRejection{Reason: "Messages must be sent to one of the space's channels"}
+		}
+
+		archived, err := s.isArchived(room)
+		if err != nil {
+			return err
+		}
+		if archived {
+			return &websocket.SendRejection{Reason: "This room is archived and no longer accepts messages"}
+		}
+
+		_, permissions, err := s.roomRepo.GetMemberPermissions(*roomID, userID)
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return &websocket.SendRejection{Reason: "You are not a member of this room"}
+			}
+			return err
+		}
+
+		if !permissions.Has(models.PermissionSendMessages) {
+			return &websocket.SendRejection{Reason: "You do not have permission to send messages in this room"}
+		}
+
+		// Moderators are exempt from announcement-only and slow mode restrictions
+		privileged := permissions.Has(models.PermissionManageRoom) || permissions.Has(models.PermissionManageMembers)
+
+		if room.AnnouncementOnly && !privileged {
+			return &websocket.SendRejection{Reason: "Only moderators can post in this announcement room"}
+		}
+
+		mute, err := s.findActiveMute(room, userID)
+		if err != nil {
+			return err
+		}
+		if mute != nil {
+			return &websocket.SendRejection{Reason: mutedReason(mute)}
+		}
+
+		if room.SlowModeSeconds > 0 && !privileged {
+			return s.checkSlowMode(room, userID)
+		}
+
+		return nil
+	}
+
+	if threadID != nil {
+		thread, err := s.dmRepo.FindThreadByID(*threadID)
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return &websocket.SendRejection{Reason: "Thread not found"}
+			}
+			return err
+		}
+
+		if thread.User1ID != userID && thread.User2ID != userID {
+			return &websocket.SendRejection{Reason: "You are not a participant in this thread"}
+		}
+		return nil
+	}
+
+	return &websocket.SendRejection{Reason: "Message must have either room_id or thread_id"}
+}
+
+// findActiveMute returns the user's active mute in the room or its space, or nil if they are not muted.
+// A mute in the space silences the user in all of its channels.
+func (s *MessageService) findActiveMute(room *models.Room, userID string) (*models.RoomMute, error) {
+	mutedIn := []string{room.RoomID}
+	if room.ParentSpaceID != nil {
+		mutedIn = append(mutedIn, *room.ParentSpaceID)
+	}
+	for _, mutedRoomID := range mutedIn {
+		mute, err := s.moderationRepo.FindActiveMute(mutedRoomID, userID)
+		if err == nil {
+			return mute, nil
+		}
+		if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func mutedReason(mute *models.RoomMute) string {
+	return "You are muted in this room until " + mute.ExpiresAt.UTC().Format(time.RFC3339)
+}
+
+// checkSlowMode rejects the message if the user posted to the room within its slow mode interval
+func (s *MessageService) checkSlowMode(room *models.Room, userID string) error {
+	last, err := s.messageRepo.FindLatestRoomMessageBySender(room.RoomID, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	interval := time.Duration(room.SlowModeSeconds) * time.Second
+	remaining := time.Until(last.CreatedAt.Add(interval))
+	if remaining <= 0 {
+		return nil
+	}
+
+	retryAfter := int(math.Ceil(remaining.Seconds()))
+	return &websocket.SendRejection{
+		Reason:     fmt.Sprintf("Slow mode is enabled; you can send another message in %d seconds", retryAfter),
+		RetryAfter: retryAfter,
+	}
+}
+
+// findThreadParent loads the message a thread reply is posted under. Threads can only be
+// started from live top-level messages in the same room.
+func (s *MessageService) findThreadParent(parentID string, roomID *string) (*models.Message, error) {
+	if roomID == nil {
+		return nil, &websocket.SendRejection{Reason: "Threads can only be started from room messages"}
+	}
+
+	parent, err := s.messageRepo.FindByID(parentID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &websocket.SendRejection{Reason: "The thread you are replying in does not exist"}
+		}
+		return nil, err
+	}
+
+	if parent.RoomID == nil || *parent.RoomID != *roomID {
+		return nil, &websocket.SendRejection{Reason: "The thread you are replying in does not exist"}
+	}
+
+	if parent.ParentMessageID != nil {
+		return nil, &websocket.SendRejection{Reason: "Threads cannot be started from a thread reply"}
+	}
+
+	if parent.DeletedAt != nil {
+		return nil, &websocket.SendRejection{Reason: "The thread's first message has been deleted"}
+	}
+
+	return parent, nil
+}
+
+// findReplyTarget loads the message being replied to, which must be a live message
+// in the same room or thread as the reply
+func (s *MessageService) findReplyTarget(parentID string, roomID, threadID *string) (*models.ReplyPreview, error) {
+	parent, err := s.messageRepo.FindByID(parentID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &websocket.SendRejection{Reason: "The message you are replying to does not exist"}
+		}
+		return nil, err
+	}
+
+	sameRoom := roomID != nil && parent.RoomID != nil && *roomID == *parent.RoomID
+	sameThread := threadID != nil && parent.ThreadID != nil && *threadID == *parent.ThreadID
+	if !sameRoom && !sameThread {
+		return nil, &websocket.SendRejection{Reason: "You can only reply to messages in the same conversation"}
+	}
+
+	if parent.DeletedAt != nil {
+		return nil, &websocket.SendRejection{Reason: "The message you are replying to has been deleted"}
+	}
+
+	var senderUsername *string
+	if parent.SenderID != nil {
+		if sender, err := s.userRepo.FindByID(*parent.SenderID); err == nil {
+			senderUsername = &sender.Username
+		}
+	}
+
+	return models.NewReplyPreview(parent, senderUsername), nil
+}
+
+// mentionPattern matches an @token that starts the content or follows a non-word character,
+// so email addresses are not treated as mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]+)`)
+
+// parsedMentions holds the mention tokens found in a message's content
+type parsedMentions struct {
+	usernames []string
+	here      bool
+	everyone  bool
+}
+
+func (p *parsedMentions) empty() bool {
+	return len(p.usernames) == 0 && !p.here && !p.everyone
+}
+
+// parseMentions extracts the distinct @usernames, @here and @everyone from content. Usernames
+// are lowercased, since they match case-insensitively. Trailing dots and hyphens are treated as
+// punctuation, as in "thanks @alice."
+func parseMentions(content string) *parsedMentions {
+	parsed := &parsedMentions{}
+	seen := make(map[string]bool)
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		token := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		switch {
+		case token == models.MentionHere:
+			parsed.here = true
+		case token == models.MentionEveryone:
+			parsed.everyone = true
+		case len(token) >= 3 && len(token) <= 50 && !seen[token]:
+			seen[token] = true
+			parsed.usernames = append(parsed.usernames, token)
+		}
+	}
+
+	return parsed
+}
+
+// resolveMentions checks the mentions in a room message against the room's audience. It stores the
+// explicitly mentioned user IDs in the message metadata and returns everyone to notify, which
+// includes online members for @here and all members for @everyone. The sender is never notified.
+func (s *MessageService) resolveMentions(message *models.Message) ([]string, error) {
+	if message.RoomID == nil {
+		return nil, nil
+	}
+
+	parsed := parseMentions(message.Content)
+	if parsed.here || parsed.everyone {
+		// Without the permission, @here and @everyone are left as plain text
+		allowed, err := s.canMentionEveryone(*message.RoomID, message.SenderID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			parsed.here, parsed.everyone = false, false
+		}
+	}
+	if parsed.empty() {
+		return nil, nil
+	}
+
+	audience, err := s.roomRepo.GetAudienceUserIDs(*message.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	inRoom := make(map[string]bool, len(audience))
+	for _, userID := range audience {
+		inRoom[userID] = true
+	}
+
+	userIDs, err := s.userRepo.FindIDsByUsernames(parsed.usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	senderID := ""
+	if message.SenderID != nil {
+		senderID = *message.SenderID
+	}
+
+	notified := make(map[string]bool)
+	var recipients []string
+	notify := func(userID string) {
+		if userID != senderID && !notified[userID] {
+			notified[userID] = true
+			recipients = append(recipients, userID)
+		}
+	}
+
+	mentioned := []string{}
+	for _, username := range parsed.usernames {
+		userID, ok := userIDs[username]
+		if !ok || !inRoom[userID] {
+			continue
+		}
+		mentioned = append(mentioned, userID)
+		notify(userID)
+	}
+
+	if parsed.everyone {
+		for _, userID := range audience {
+			notify(userID)
+		}
+	} else if parsed.here {
+		for _, userID := range audience {
+			if s.hub.IsOnline(userID) {
+				notify(userID)
+			}
+		}
+	}
+
+	if len(mentioned) == 0 && !parsed.here && !parsed.everyone {
+		return nil, nil
+	}
+
+	if message.Metadata == nil {
+		message.Metadata = &models.Metadata{}
+	}
+	metadata := *message.Metadata
+	metadata["mentions"] = mentioned
+	metadata["mention_here"] = parsed.here
+	metadata["mention_everyone"] = parsed.everyone
+
+	return recipients, nil
+}
+
+// canMentionEveryone reports whether the sender holds the mention_everyone permission in the room
+func (s *MessageService) canMentionEveryone(roomID string, senderID *string) (bool, error) {
+	if senderID == nil {
+		return false, nil
+	}
+
+	_, permissions, err := s.roomRepo.GetMemberPermissions(roomID, *senderID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return permissions.Has(models.PermissionMentionEveryone), nil
+}
+
+// notifyMentions records the mentions of a stored message and sends each recipient a mention event
+func (s *MessageService) notifyMentions(message *models.Message, recipients []string) {
+	if len(recipients) == 0 {
+		return
+	}
+
+	if err := s.mentionRepo.CreateForMessage(message, recipients); err != nil {
+		log.Printf("Error storing mentions for message %s: %v", message.MessageID, err)
+	}
+
+	s.hub.SendMention(message, recipients)
+}
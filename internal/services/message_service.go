@@ -3,84 +3,724 @@ package services
 import (
 	"converse/internal/models"
 	"converse/internal/repositories"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"converse/pkg/errors"
+	"encoding/base64"
+	stderrors "errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
 )
 
 // MessageService handles business logic for messages
 type MessageService struct {
-	messageRepo *repositories.MessageRepository
+	roomAccess
+	messageRepo    *repositories.MessageRepository
+	dmRepo         *repositories.DirectMessageRepository
+	userRepo       *repositories.UserRepository
+	moderationRepo *repositories.RoomModerationRepository
+	reactionRepo   *repositories.MessageReactionRepository
+	followerRepo   *repositories.ThreadFollowerRepository
+	mentionRepo    *repositories.MessageMentionRepository
+	hub            *websocket.Hub
 }
 
 // NewMessageService creates a new message service
-func NewMessageService() *MessageService {
+func NewMessageService(hub *websocket.Hub) *MessageService {
 	return &MessageService{
-		messageRepo: repositories.NewMessageRepository(),
+		roomAccess:     roomAccess{roomRepo: repositories.NewRoomRepository()},
+		messageRepo:    repositories.NewMessageRepository(),
+		dmRepo:         repositories.NewDirectMessageRepository(),
+		userRepo:       repositories.NewUserRepository(),
+		moderationRepo: repositories.NewRoomModerationRepository(),
+		reactionRepo:   repositories.NewMessageReactionRepository(),
+		followerRepo:   repositories.NewThreadFollowerRepository(),
+		mentionRepo:    repositories.NewMessageMentionRepository(),
+		hub:            hub,
 	}
 }
 
-// PaginatedMessages represents a paginated response of messages
-type PaginatedMessages struct {
-	Messages    []*models.Message `json:"messages"`
-	CurrentPage int               `json:"current_page"`
-	PageSize    int               `json:"page_size"`
-	HasMore     bool              `json:"has_more"`
+// MessagePage is a window of a conversation's history, newest message first.
+// NextCursor continues towards older messages (pass it as before) and PrevCursor towards
+// newer ones (pass it as after); each is nil when there is nothing more in that direction.
+type MessagePage struct {
+	Messages   []*models.MessageView `json:"messages"`
+	NextCursor *string               `json:"next_cursor"`
+	PrevCursor *string               `json:"prev_cursor"`
+}
+
+// messagePageFetcher reads one side of a cursor within a single room or thread
+type messagePageFetcher func(cursor *repositories.MessageCursor, direction repositories.PageDirection, limit int) ([]*models.Message, error)
+
+// GetMessagesByRoomID retrieves a page of a room's history around the requested cursor
+func (s *MessageService) GetMessagesByRoomID(roomID, userID string, query types.MessagePageQuery) (*MessagePage, error) {
+	if err := s.checkRoomAccess(roomID, userID); err != nil {
+		return nil, err
+	}
+
+	fetch := func(cursor *repositories.MessageCursor, direction repositories.PageDirection, limit int) ([]*models.Message, error) {
+		return s.messageRepo.GetMessagesByRoomID(roomID, cursor, direction, limit)
+	}
+	belongs := func(message *models.Message) bool {
+		return message.RoomID != nil && *message.RoomID == roomID
+	}
+
+	return s.getMessagePage(userID, query, fetch, belongs)
 }
 
-// GetMessagesByRoomID retrieves paginated messages for a specific room
-func (s *MessageService) GetMessagesByRoomID(roomID string, page, pageSize int) (*PaginatedMessages, error) {
-	// Calculate offset from page and pageSize
-	offset := (page - 1) * pageSize
-	
-	// Get one more message than requested to determine if there are more pages
-	limit := pageSize + 1
-	
-	// Fetch messages from repository
-	messages, err := s.messageRepo.GetMessagesByRoomID(roomID, limit, offset)
+// GetMessagesByThreadID retrieves a page of a DM thread's history around the requested cursor
+func (s *MessageService) GetMessagesByThreadID(threadID, userID string, query types.MessagePageQuery) (*MessagePage, error) {
+	if err := s.checkThreadAccess(threadID, userID); err != nil {
+		return nil, err
+	}
+
+	fetch := func(cursor *repositories.MessageCursor, direction repositories.PageDirection, limit int) ([]*models.Message, error) {
+		return s.messageRepo.GetMessagesByThreadID(threadID, cursor, direction, limit)
+	}
+	belongs := func(message *models.Message) bool {
+		return message.ThreadID != nil && *message.ThreadID == threadID
+	}
+
+	return s.getMessagePage(userID, query, fetch, belongs)
+}
+
+// GetThreadReplies retrieves a page of the replies in the thread started by a room message
+func (s *MessageService) GetThreadReplies(messageID, userID string, query types.MessagePageQuery) (*MessagePage, error) {
+	if _, err := s.findThreadRoot(messageID, userID); err != nil {
+		return nil, err
+	}
+
+	fetch := func(cursor *repositories.MessageCursor, direction repositories.PageDirection, limit int) ([]*models.Message, error) {
+		return s.messageRepo.GetThreadReplies(messageID, cursor, direction, limit)
+	}
+	belongs := func(message *models.Message) bool {
+		return message.ParentMessageID != nil && *message.ParentMessageID == messageID
+	}
+
+	return s.getMessagePage(userID, query, fetch, belongs)
+}
+
+// FollowThread subscribes the user to replies in the thread started by a room message
+func (s *MessageService) FollowThread(messageID, userID string) error {
+	if _, err := s.findThreadRoot(messageID, userID); err != nil {
+		return err
+	}
+	return s.followerRepo.Follow(messageID, userID)
+}
+
+// UnfollowThread stops notifying the user about replies in the thread
+func (s *MessageService) UnfollowThread(messageID, userID string) error {
+	if _, err := s.findThreadRoot(messageID, userID); err != nil {
+		return err
+	}
+	return s.followerRepo.Unfollow(messageID, userID)
+}
+
+// findThreadRoot returns a top-level message in a room the user can read, which may start a thread.
+// Deleted roots are allowed so the replies under their tombstone stay reachable.
+func (s *MessageService) findThreadRoot(messageID, userID string) (*models.Message, error) {
+	message, err := s.messageRepo.FindByID(messageID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Thread not found")
+		}
+		return nil, err
+	}
+
+	if message.RoomID == nil || message.ParentMessageID != nil {
+		return nil, errors.NewNotFoundError("Thread not found")
+	}
+
+	if err := s.checkRoomAccess(*message.RoomID, userID); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// getMessagePage loads the page selected by the query and attaches reactions and reply previews.
+// belongs reports whether a message is part of the conversation, for the around target.
+func (s *MessageService) getMessagePage(userID string, query types.MessagePageQuery, fetch messagePageFetcher, belongs func(*models.Message) bool) (*MessagePage, error) {
+	findTarget := func(messageID string) (*models.Message, error) {
+		target, err := s.messageRepo.FindByID(messageID)
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.NewNotFoundError("Message not found")
+			}
+			return nil, err
+		}
+		if !belongs(target) {
+			return nil, errors.NewNotFoundError("Message not found")
+		}
+		return target, nil
+	}
+
+	window, err := pageMessages(query, fetch, findTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := s.toViews(userID, window.messages)
 	if err != nil {
 		return nil, err
 	}
 
-	// Determine if there are more messages
-	hasMore := false
-	if len(messages) > pageSize {
-		hasMore = true
-		// Remove the extra message we fetched to check if there are more
-		messages = messages[:pageSize]
+	return &MessagePage{Messages: views, NextCursor: window.nextCursor, PrevCursor: window.prevCursor}, nil
+}
+
+// messageWindow is a run of messages, newest first, with the cursors leading past either end
+type messageWindow struct {
+	messages   []*models.Message
+	nextCursor *string
+	prevCursor *string
+}
+
+// pageMessages resolves at most one of the before, after and around cursors into a window.
+// Each side is fetched with one extra row to tell whether more messages lie beyond it.
+// It reads only through fetch and findTarget, which loads the around message.
+func pageMessages(query types.MessagePageQuery, fetch messagePageFetcher, findTarget func(messageID string) (*models.Message, error)) (*messageWindow, error) {
+	cursors := 0
+	for _, value := range []string{query.Before, query.After, query.Around} {
+		if value != "" {
+			cursors++
+		}
+	}
+	if cursors > 1 {
+		return nil, errors.NewBadRequestError("Invalid cursor", "Use only one of before, after and around")
+	}
+
+	limit := query.Limit
+	var messages []*models.Message
+	var hasOlder, hasNewer bool
+
+	switch {
+	case query.Around != "":
+		target, err := findTarget(query.Around)
+		if err != nil {
+			return nil, err
+		}
+
+		cursor := cursorFor(target)
+		olderLimit := (limit - 1) / 2
+		newerLimit := limit - 1 - olderLimit
+
+		older, err := fetch(cursor, repositories.PageOlder, olderLimit+1)
+		if err != nil {
+			return nil, err
+		}
+		newer, err := fetch(cursor, repositories.PageNewer, newerLimit+1)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(older) > olderLimit {
+			hasOlder = true
+			older = older[:olderLimit]
+		}
+		if len(newer) > newerLimit {
+			hasNewer = true
+			newer = newer[len(newer)-newerLimit:]
+		}
+
+		messages = append(messages, newer...)
+		messages = append(messages, target)
+		messages = append(messages, older...)
+
+	case query.After != "":
+		cursor, err := decodeCursor(query.After)
+		if err != nil {
+			return nil, err
+		}
+
+		messages, err = fetch(cursor, repositories.PageNewer, limit+1)
+		if err != nil {
+			return nil, err
+		}
+
+		hasOlder = true
+		if len(messages) > limit {
+			hasNewer = true
+			messages = messages[len(messages)-limit:]
+		}
+
+	default:
+		var cursor *repositories.MessageCursor
+		if query.Before != "" {
+			var err error
+			if cursor, err = decodeCursor(query.Before); err != nil {
+				return nil, err
+			}
+			hasNewer = true
+		}
+
+		var err error
+		messages, err = fetch(cursor, repositories.PageOlder, limit+1)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(messages) > limit {
+			hasOlder = true
+			messages = messages[:limit]
+		}
+	}
+
+	window := &messageWindow{messages: messages}
+	if len(messages) == 0 {
+		// Nothing on this side; hand back the request cursor so the client can resume from it
+		if hasNewer && query.Before != "" {
+			window.prevCursor = &query.Before
+		}
+		if hasOlder && query.After != "" {
+			window.nextCursor = &query.After
+		}
+		return window, nil
 	}
 
-	return &PaginatedMessages{
-		Messages:    messages,
-		CurrentPage: page,
-		PageSize:    pageSize,
-		HasMore:     hasMore,
-	}, nil
+	if hasOlder {
+		next := encodeCursor(messages[len(messages)-1])
+		window.nextCursor = &next
+	}
+	if hasNewer {
+		prev := encodeCursor(messages[0])
+		window.prevCursor = &prev
+	}
+
+	return window, nil
+}
+
+// encodeCursor turns a message's position into an opaque cursor string
+func encodeCursor(message *models.Message) string {
+	raw := message.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + message.MessageID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
 }
 
-// GetMessagesByThreadID retrieves paginated messages for a specific thread
-func (s *MessageService) GetMessagesByThreadID(threadID string, page, pageSize int) (*PaginatedMessages, error) {
-	// Calculate offset from page and pageSize
-	offset := (page - 1) * pageSize
-	
-	// Get one more message than requested to determine if there are more pages
-	limit := pageSize + 1
-	
-	// Fetch messages from repository
-	messages, err := s.messageRepo.GetMessagesByThreadID(threadID, limit, offset)
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(value string) (*repositories.MessageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalidCursorError()
+	}
+
+	cursor, ok := parseMessagePosition(string(raw))
+	if !ok {
+		return nil, invalidCursorError()
+	}
+	return cursor, nil
+}
+
+// encodeSearchCursor turns a search hit's position in the results into an opaque cursor string
+func encodeSearchCursor(hit *models.MessageSearchHit) string {
+	raw := strconv.FormatFloat(hit.Relevance, 'g', -1, 64) + "|" +
+		hit.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + hit.MessageID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSearchCursor parses a cursor produced by encodeSearchCursor
+func decodeSearchCursor(value string) (*repositories.SearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalidCursorError()
+	}
+
+	relevance, position, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, invalidCursorError()
+	}
+	score, err := strconv.ParseFloat(relevance, 64)
+	if err != nil {
+		return nil, invalidCursorError()
+	}
+	cursor, ok := parseMessagePosition(position)
+	if !ok {
+		return nil, invalidCursorError()
+	}
+
+	return &repositories.SearchCursor{Relevance: score, MessageCursor: *cursor}, nil
+}
+
+func invalidCursorError() error {
+	return errors.NewBadRequestError("Invalid cursor", "The cursor is malformed")
+}
+
+// parseMessagePosition parses the "created_at|message_id" part of a cursor
+func parseMessagePosition(raw string) (*repositories.MessageCursor, bool) {
+	createdAt, messageID, found := strings.Cut(raw, "|")
+	if !found || messageID == "" {
+		return nil, false
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, false
+	}
+
+	return &repositories.MessageCursor{CreatedAt: timestamp, MessageID: messageID}, true
+}
+
+func cursorFor(message *models.Message) *repositories.MessageCursor {
+	return &repositories.MessageCursor{CreatedAt: message.CreatedAt, MessageID: message.MessageID}
+}
+
+// searchContextSize is how many neighbouring messages are returned on each side of a search hit
+const searchContextSize = 2
+
+// MessageSearchResult is a search hit with the messages immediately around it, newest first,
+// so clients can preview it and then open the conversation with around=<message_id>
+type MessageSearchResult struct {
+	*models.MessageSearchHit
+	Reactions     []*models.ReactionSummary `json:"reactions"`
+	ReplyTo       *models.ReplyPreview      `json:"reply_to,omitempty"`
+	ContextBefore []*models.MessageView     `json:"context_before"`
+	ContextAfter  []*models.MessageView     `json:"context_after"`
+}
+
+// SearchResultPage is a page of message search results, most relevant first. NextCursor
+// continues with less relevant results (pass it as before) and is nil when there are none.
+type SearchResultPage struct {
+	Results    []*MessageSearchResult `json:"results"`
+	NextCursor *string                `json:"next_cursor"`
+}
+
+// SearchMessages finds messages matching the query in the rooms and DM threads the user takes part in,
+// continuing after the before cursor when one is given
+func (s *MessageService) SearchMessages(userID string, query types.SearchMessagesQuery, before string, limit int) (*SearchResultPage, error) {
+	booleanQuery := buildBooleanQuery(query.Query)
+	if booleanQuery == "" {
+		return nil, errors.NewBadRequestError("Invalid search query", "The query must contain at least one word of three or more letters that is not a common word like \"the\"")
+	}
+
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return nil, errors.NewBadRequestError("Invalid date range", "from must not be after to")
+	}
+
+	filter := repositories.MessageSearchFilter{
+		SenderID:    query.SenderID,
+		RoomID:      query.RoomID,
+		ThreadID:    query.ThreadID,
+		ContentType: query.ContentType,
+		From:        query.From,
+		To:          query.To,
+	}
+
+	var cursor *repositories.SearchCursor
+	if before != "" {
+		var err error
+		if cursor, err = decodeSearchCursor(before); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get one more hit than requested to determine if there are more results
+	hits, err := s.messageRepo.SearchMessages(userID, booleanQuery, filter, cursor, limit+1)
 	if err != nil {
 		return nil, err
 	}
 
-	// Determine if there are more messages
-	hasMore := false
-	if len(messages) > pageSize {
-		hasMore = true
-		// Remove the extra message we fetched to check if there are more
-		messages = messages[:pageSize]
+	hasMore := len(hits) > limit
+	if hasMore {
+		hits = hits[:limit]
+	}
+
+	// Load every hit's context first so reactions and reply previews can be fetched in one batch
+	hitMessages := make([]*models.Message, 0, len(hits))
+	for _, hit := range hits {
+		hitMessages = append(hitMessages, &hit.Message)
+	}
+	contextsBefore, contextsAfter, err := s.messageRepo.GetSearchContexts(hitMessages, searchContextSize)
+	if err != nil {
+		return nil, err
+	}
+
+	shown := hitMessages
+	for _, hit := range hits {
+		shown = append(shown, contextsBefore[hit.MessageID]...)
+		shown = append(shown, contextsAfter[hit.MessageID]...)
+	}
+
+	decorations, err := s.loadDecorations(userID, shown)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*MessageSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, &MessageSearchResult{
+			MessageSearchHit: hit,
+			Reactions:        decorations.reactionsFor(hit.MessageID),
+			ReplyTo:          decorations.replyTo(&hit.Message),
+			ContextBefore:    decorations.views(contextsBefore[hit.MessageID]),
+			ContextAfter:     decorations.views(contextsAfter[hit.MessageID]),
+		})
+	}
+
+	page := &SearchResultPage{Results: results}
+	if hasMore {
+		next := encodeSearchCursor(hits[len(hits)-1])
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// MentionPage is a page of messages that mentioned the user, newest first. NextCursor
+// continues towards older mentions (pass it as before) and is nil when there are none.
+type MentionPage struct {
+	Mentions   []*models.MessageView `json:"mentions"`
+	NextCursor *string               `json:"next_cursor"`
+}
+
+// GetMentions lists the messages that mentioned the user in rooms they can still read, older than
+// the before cursor when one is given
+func (s *MessageService) GetMentions(userID, before string, limit int) (*MentionPage, error) {
+	var cursor *repositories.MessageCursor
+	if before != "" {
+		var err error
+		if cursor, err = decodeCursor(before); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get one more message than requested to determine if there are older mentions
+	messages, err := s.mentionRepo.GetRecentForUser(userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(messages) > limit
+	if hasMore {
+		messages = messages[:limit]
+	}
+
+	views, err := s.toViews(userID, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &MentionPage{Mentions: views}
+	if hasMore {
+		next := encodeCursor(messages[len(messages)-1])
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// toViews attaches each message's aggregated reactions, as seen by the user, and reply preview
+func (s *MessageService) toViews(userID string, messages []*models.Message) ([]*models.MessageView, error) {
+	decorations, err := s.loadDecorations(userID, messages)
+	if err != nil {
+		return nil, err
+	}
+	return decorations.views(messages), nil
+}
+
+// messageDecorations holds the per-message extras shown alongside a batch of messages
+type messageDecorations struct {
+	reactions map[string][]*models.ReactionSummary
+	replies   map[string]*models.ReplyPreview
+}
+
+// loadDecorations fetches reactions and reply previews for a batch of messages in two queries
+func (s *MessageService) loadDecorations(userID string, messages []*models.Message) (*messageDecorations, error) {
+	reactions, err := s.reactionRepo.GetSummaries(messageIDs(messages), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var parentIDs []string
+	for _, message := range messages {
+		if message.ReplyToMessageID != nil {
+			parentIDs = append(parentIDs, *message.ReplyToMessageID)
+		}
+	}
+
+	replies, err := s.messageRepo.GetReplyPreviews(parentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messageDecorations{reactions: reactions, replies: replies}, nil
+}
+
+func (d *messageDecorations) views(messages []*models.Message) []*models.MessageView {
+	views := make([]*models.MessageView, 0, len(messages))
+	for _, message := range messages {
+		views = append(views, &models.MessageView{
+			Message:   *message,
+			Reactions: d.reactionsFor(message.MessageID),
+			ReplyTo:   d.replyTo(message),
+		})
+	}
+	return views
+}
+
+// reactionsFor keeps messages without reactions serialized as [] rather than null
+func (d *messageDecorations) reactionsFor(messageID string) []*models.ReactionSummary {
+	if reactions, ok := d.reactions[messageID]; ok {
+		return reactions
+	}
+	return []*models.ReactionSummary{}
+}
+
+func (d *messageDecorations) replyTo(message *models.Message) *models.ReplyPreview {
+	if message.ReplyToMessageID == nil {
+		return nil
+	}
+	return d.replies[*message.ReplyToMessageID]
+}
+
+func messageIDs(messages []*models.Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.MessageID)
+	}
+	return ids
+}
+
+// minSearchWordLength matches InnoDB's default innodb_ft_min_token_size; shorter words are not indexed
+const minSearchWordLength = 3
+
+// searchStopwords is InnoDB's default full-text stopword list (INFORMATION_SCHEMA.INNODB_FT_DEFAULT_STOPWORD).
+// These words are not indexed, so requiring one would match nothing.
+var searchStopwords = map[string]bool{
+	"a": true, "about": true, "an": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"com": true, "de": true, "en": true, "for": true, "from": true, "how": true, "i": true, "in": true,
+	"is": true, "it": true, "la": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "what": true, "when": true, "where": true, "who": true,
+	"will": true, "with": true, "und": true, "www": true,
+}
+
+// buildBooleanQuery turns free text into a MySQL boolean-mode query requiring every word,
+// each as a prefix. Operator characters are dropped so user input cannot alter the query, and
+// words the full-text index leaves out are skipped rather than required.
+func buildBooleanQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if utf8.RuneCountInString(word) < minSearchWordLength || searchStopwords[strings.ToLower(word)] {
+			continue
+		}
+		terms = append(terms, "+"+word+"*")
+	}
+	return strings.Join(terms, " ")
+}
+
+// GetRoomReadMarkers lists how far each member of a room visible to the user has read
+func (s *MessageService) GetRoomReadMarkers(roomID, userID string) ([]*models.ReadMarker, error) {
+	if err := s.checkRoomAccess(roomID, userID); err != nil {
+		return nil, err
+	}
+	return s.roomRepo.GetReadMarkers(roomID)
+}
+
+// GetThreadReadMarkers lists how far each participant of a DM thread has read
+func (s *MessageService) GetThreadReadMarkers(threadID, userID string) ([]*models.ReadMarker, error) {
+	if err := s.checkThreadAccess(threadID, userID); err != nil {
+		return nil, err
+	}
+
+	thread, err := s.dmRepo.FindThreadByID(threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	markers := []*models.ReadMarker{}
+	if thread.User1LastSeenMessageID != "" {
+		markers = append(markers, &models.ReadMarker{UserID: thread.User1ID, LastSeenMessageID: thread.User1LastSeenMessageID})
+	}
+	if thread.User2LastSeenMessageID != "" {
+		markers = append(markers, &models.ReadMarker{UserID: thread.User2ID, LastSeenMessageID: thread.User2LastSeenMessageID})
+	}
+	return markers, nil
+}
+
+// GetRoomPins lists the pinned messages of a room visible to the user
+func (s *MessageService) GetRoomPins(roomID, userID string) ([]*models.MessageView, error) {
+	if err := s.checkRoomAccess(roomID, userID); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.messageRepo.GetPinnedByRoomID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toViews(userID, messages)
+}
+
+// GetThreadPins lists the pinned messages of a DM thread the user takes part in
+func (s *MessageService) GetThreadPins(threadID, userID string) ([]*models.MessageView, error) {
+	if err := s.checkThreadAccess(threadID, userID); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.messageRepo.GetPinnedByThreadID(threadID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toViews(userID, messages)
+}
+
+// GetEditHistory returns the prior versions of a message the user can read
+func (s *MessageService) GetEditHistory(messageID, userID string) ([]*models.MessageEdit, error) {
+	if _, err := s.findReadableMessage(messageID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.messageRepo.GetEditHistory(messageID)
+}
+
+// findReadableMessage returns a live message from a room or thread the user can read
+func (s *MessageService) findReadableMessage(messageID, userID string) (*models.Message, error) {
+	message, err := s.messageRepo.FindByID(messageID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Message not found")
+		}
+		return nil, err
+	}
+
+	if message.DeletedAt != nil {
+		return nil, errors.NewNotFoundError("Message not found")
+	}
+
+	if message.RoomID != nil {
+		err = s.checkRoomAccess(*message.RoomID, userID)
+	} else if message.ThreadID != nil {
+		err = s.checkThreadAccess(*message.ThreadID, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// checkRoomAccess allows reading any room visible to the user
+func (s *MessageService) checkRoomAccess(roomID, userID string) error {
+	_, err := s.findVisibleRoom(roomID, userID)
+	return err
+}
+
+// checkThreadAccess only allows the two participants of a DM thread to read it
+func (s *MessageService) checkThreadAccess(threadID, userID string) error {
+	thread, err := s.dmRepo.FindThreadByID(threadID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFoundError("Thread not found")
+		}
+		return err
+	}
+
+	if thread.User1ID != userID && thread.User2ID != userID {
+		return errors.NewNotFoundError("Thread not found")
 	}
 
-	return &PaginatedMessages{
-		Messages:    messages,
-		CurrentPage: page,
-		PageSize:    pageSize,
-		HasMore:     hasMore,
-	}, nil
+	return nil
 }
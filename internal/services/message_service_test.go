@@ -0,0 +1,264 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/types"
+	"converse/pkg/errors"
+	"encoding/base64"
+	stderrors "errors"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testHistory is a conversation of nine messages, oldest first, one minute apart except that
+// m4 and m5 share a timestamp and are ordered by message ID
+func testHistory() []*models.Message {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	minutes := []int{1, 2, 3, 4, 4, 6, 7, 8, 9}
+
+	history := make([]*models.Message, 0, len(minutes))
+	for i, minute := range minutes {
+		history = append(history, &models.Message{
+			MessageID: "m" + string(rune('1'+i)),
+			CreatedAt: base.Add(time.Duration(minute) * time.Minute),
+		})
+	}
+	return history
+}
+
+// olderThan reports whether the message comes before the cursor in (created_at, message_id) order
+func olderThan(message *models.Message, cursor *repositories.MessageCursor) bool {
+	if !message.CreatedAt.Equal(cursor.CreatedAt) {
+		return message.CreatedAt.Before(cursor.CreatedAt)
+	}
+	return message.MessageID < cursor.MessageID
+}
+
+// memoryFetcher reads history the way MessageRepository.getMessagePage reads a conversation:
+// the rows nearest the cursor win the limit, and both directions are returned newest first
+func memoryFetcher(history []*models.Message) messagePageFetcher {
+	return func(cursor *repositories.MessageCursor, direction repositories.PageDirection, limit int) ([]*models.Message, error) {
+		var messages []*models.Message
+		if direction == repositories.PageOlder {
+			for i := len(history) - 1; i >= 0 && len(messages) < limit; i-- {
+				if cursor == nil || olderThan(history[i], cursor) {
+					messages = append(messages, history[i])
+				}
+			}
+			return messages, nil
+		}
+
+		for i := 0; i < len(history) && len(messages) < limit; i++ {
+			if cursor == nil || (history[i].MessageID != cursor.MessageID && !olderThan(history[i], cursor)) {
+				messages = append([]*models.Message{history[i]}, messages...)
+			}
+		}
+		return messages, nil
+	}
+}
+
+func TestPageMessages(t *testing.T) {
+	history := testHistory()
+	byID := make(map[string]*models.Message, len(history))
+	for _, message := range history {
+		byID[message.MessageID] = message
+	}
+	cursorAt := func(messageID string) string { return encodeCursor(byID[messageID]) }
+
+	fetch := memoryFetcher(history)
+	findTarget := func(messageID string) (*models.Message, error) {
+		if message, ok := byID[messageID]; ok {
+			return message, nil
+		}
+		return nil, errors.NewNotFoundError("Message not found")
+	}
+
+	tests := []struct {
+		name     string
+		query    types.MessagePageQuery
+		want     string // message IDs, newest first
+		wantNext string // the message the next cursor points at, or "" for none
+		wantPrev string
+	}{
+		{"newest, everything fits", types.MessagePageQuery{Limit: 20}, "m9 m8 m7 m6 m5 m4 m3 m2 m1", "", ""},
+		{"newest, exactly fits", types.MessagePageQuery{Limit: 9}, "m9 m8 m7 m6 m5 m4 m3 m2 m1", "", ""},
+		{"newest page", types.MessagePageQuery{Limit: 3}, "m9 m8 m7", "m7", ""},
+		{"before, middle", types.MessagePageQuery{Before: cursorAt("m7"), Limit: 3}, "m6 m5 m4", "m4", "m6"},
+		{"before, tied timestamp", types.MessagePageQuery{Before: cursorAt("m5"), Limit: 3}, "m4 m3 m2", "m2", "m4"},
+		{"before, reaching the oldest", types.MessagePageQuery{Before: cursorAt("m3"), Limit: 3}, "m2 m1", "", "m2"},
+		{"before the oldest", types.MessagePageQuery{Before: cursorAt("m1"), Limit: 3}, "", "", "m1"},
+		{"after, middle", types.MessagePageQuery{After: cursorAt("m2"), Limit: 3}, "m5 m4 m3", "m3", "m5"},
+		{"after, tied timestamp", types.MessagePageQuery{After: cursorAt("m4"), Limit: 3}, "m7 m6 m5", "m5", "m7"},
+		{"after, reaching the newest", types.MessagePageQuery{After: cursorAt("m7"), Limit: 3}, "m9 m8", "m8", ""},
+		{"after the newest", types.MessagePageQuery{After: cursorAt("m9"), Limit: 3}, "", "m9", ""},
+		{"around, odd limit", types.MessagePageQuery{Around: "m5", Limit: 5}, "m7 m6 m5 m4 m3", "m3", "m7"},
+		{"around, even limit leans newer", types.MessagePageQuery{Around: "m5", Limit: 4}, "m7 m6 m5 m4", "m4", "m7"},
+		{"around, near the newest", types.MessagePageQuery{Around: "m8", Limit: 5}, "m9 m8 m7 m6", "m6", ""},
+		{"around the oldest", types.MessagePageQuery{Around: "m1", Limit: 3}, "m2 m1", "", "m2"},
+		{"around, only the target", types.MessagePageQuery{Around: "m1", Limit: 1}, "m1", "", "m1"},
+		{"around, whole history", types.MessagePageQuery{Around: "m4", Limit: 50}, "m9 m8 m7 m6 m5 m4 m3 m2 m1", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := pageMessages(tt.query, fetch, findTarget)
+			if err != nil {
+				t.Fatalf("pageMessages: %v", err)
+			}
+
+			ids := make([]string, 0, len(window.messages))
+			for _, message := range window.messages {
+				ids = append(ids, message.MessageID)
+			}
+			if got := strings.Join(ids, " "); got != tt.want {
+				t.Errorf("messages = %q, want %q", got, tt.want)
+			}
+			if got := cursorMessageID(t, window.nextCursor); got != tt.wantNext {
+				t.Errorf("next cursor points at %q, want %q", got, tt.wantNext)
+			}
+			if got := cursorMessageID(t, window.prevCursor); got != tt.wantPrev {
+				t.Errorf("prev cursor points at %q, want %q", got, tt.wantPrev)
+			}
+		})
+	}
+}
+
+func TestPageMessagesErrors(t *testing.T) {
+	history := testHistory()
+	fetch := memoryFetcher(history)
+	findTarget := func(messageID string) (*models.Message, error) {
+		return nil, errors.NewNotFoundError("Message not found")
+	}
+	cursor := encodeCursor(history[4])
+
+	tests := []struct {
+		name     string
+		query    types.MessagePageQuery
+		wantCode int
+	}{
+		{"before and after", types.MessagePageQuery{Before: cursor, After: cursor, Limit: 3}, http.StatusBadRequest},
+		{"before and around", types.MessagePageQuery{Before: cursor, Around: "m5", Limit: 3}, http.StatusBadRequest},
+		{"malformed before", types.MessagePageQuery{Before: "not a cursor", Limit: 3}, http.StatusBadRequest},
+		{"malformed after", types.MessagePageQuery{After: "bTU", Limit: 3}, http.StatusBadRequest},
+		{"unknown around target", types.MessagePageQuery{Around: "missing", Limit: 3}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pageMessages(tt.query, fetch, findTarget)
+			var appErr *errors.AppError
+			if !stderrors.As(err, &appErr) || appErr.Code != tt.wantCode {
+				t.Errorf("err = %v, want a %d error", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+// cursorMessageID decodes a page cursor to the message it points at, or "" for nil
+func cursorMessageID(t *testing.T, cursor *string) string {
+	t.Helper()
+	if cursor == nil {
+		return ""
+	}
+	position, err := decodeCursor(*cursor)
+	if err != nil {
+		t.Fatalf("decodeCursor(%q): %v", *cursor, err)
+	}
+	return position.MessageID
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		createdAt time.Time
+	}{
+		{"whole seconds", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"nanoseconds", time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)},
+		{"other zone", time.Date(2024, 3, 1, 14, 30, 0, 500, time.FixedZone("CEST", 2*60*60))},
+	}
+
+	for _, tt := range tests {
+		message := &models.Message{MessageID: "6f1c0f5e-8d1a-4d3b-9a57-3c2b1e0d9f8a", CreatedAt: tt.createdAt}
+		cursor, err := decodeCursor(encodeCursor(message))
+		if err != nil {
+			t.Errorf("%s: decodeCursor: %v", tt.name, err)
+			continue
+		}
+		if !cursor.CreatedAt.Equal(tt.createdAt) || cursor.MessageID != message.MessageID {
+			t.Errorf("%s: decoded %v %s, want %v %s", tt.name, cursor.CreatedAt, cursor.MessageID, tt.createdAt, message.MessageID)
+		}
+	}
+}
+
+func TestDecodeCursorMalformed(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := map[string]string{
+		"empty":                "",
+		"not base64":           "not a cursor!",
+		"padded base64":        base64.URLEncoding.EncodeToString([]byte("2024-03-01T12:00:00Z|m1")),
+		"no separator":         encode("2024-03-01T12:00:00Z"),
+		"no message ID":        encode("2024-03-01T12:00:00Z|"),
+		"not a timestamp":      encode("yesterday|m1"),
+		"search cursor":        encode("1.5|2024-03-01T12:00:00Z|m1"),
+		"timestamp without tz": encode("2024-03-01T12:00:00|m1"),
+	}
+
+	for name, value := range tests {
+		_, err := decodeCursor(value)
+		var appErr *errors.AppError
+		if !stderrors.As(err, &appErr) || appErr.Code != http.StatusBadRequest {
+			t.Errorf("%s: err = %v, want a 400 error", name, err)
+		}
+	}
+}
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)
+
+	for _, relevance := range []float64{0, 1e-7, 0.1234567890123, 3, math.Nextafter(2, 3)} {
+		hit := &models.MessageSearchHit{Message: models.Message{MessageID: "m1", CreatedAt: createdAt}, Relevance: relevance}
+		cursor, err := decodeSearchCursor(encodeSearchCursor(hit))
+		if err != nil {
+			t.Errorf("relevance %v: decodeSearchCursor: %v", relevance, err)
+			continue
+		}
+		// The cursor is compared with MATCH() in SQL, so the score must survive exactly
+		if cursor.Relevance != relevance || !cursor.CreatedAt.Equal(createdAt) || cursor.MessageID != "m1" {
+			t.Errorf("relevance %v: decoded %+v", relevance, cursor)
+		}
+	}
+
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	for _, value := range []string{"", encode("1.5"), encode("high|2024-03-01T12:00:00Z|m1"), encode("1.5|2024-03-01T12:00:00Z"), encode("2024-03-01T12:00:00Z|m1")} {
+		if _, err := decodeSearchCursor(value); err == nil {
+			t.Errorf("decodeSearchCursor(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestBuildBooleanQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"deploy failed", "+deploy* +failed*"},
+		{"the deploy of prod", "+deploy* +prod*"},
+		{"The WITH about", ""},
+		{"go is ok", ""},
+		{"+db -prod \"release\" (notes)", "+prod* +release* +notes*"},
+		{"café über", "+café* +über*"},
+		{"v1.2 build", "+build*"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := buildBooleanQuery(tt.query); got != tt.want {
+			t.Errorf("buildBooleanQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
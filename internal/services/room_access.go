@@ -0,0 +1,177 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/pkg/errors"
+	stderrors "errors"
+
+	"gorm.io/gorm"
+)
+
+// roomAccess bundles the lookups and permission checks shared by the room services
+type roomAccess struct {
+	roomRepo *repositories.RoomRepository
+}
+
+func (a roomAccess) findRoom(roomID string) (*models.Room, error) {
+	room, err := a.roomRepo.FindByID(roomID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Room not found")
+		}
+		return nil, err
+	}
+	return room, nil
+}
+
+// findVisibleRoom returns the room if the user may see it. Public rooms are visible to everyone,
+// public channels to anyone who can see their space and is not banned from it or the channel,
+// and anything else only to its members.
+// Hidden rooms are reported as not found so their existence is not leaked.
+func (a roomAccess) findVisibleRoom(roomID, userID string) (*models.Room, error) {
+	room, err := a.findRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !room.IsPrivate {
+		if room.ParentSpaceID == nil {
+			return room, nil
+		}
+
+		_, err := a.findVisibleRoom(*room.ParentSpaceID, userID)
+		if err == nil {
+			// A ban from the channel or its space hides the channel, as it does for members reaching it through the space
+			banned, err := a.roomRepo.IsBannedFromChannel(room, userID)
+			if err != nil {
+				return nil, err
+			}
+			if banned {
+				return nil, errors.NewNotFoundError("Room not found")
+			}
+			return room, nil
+		}
+		if _, ok := err.(*errors.AppError); !ok {
+			return nil, err
+		}
+	}
+
+	if _, _, err := a.roomRepo.GetMemberPermissions(roomID, userID); err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Room not found")
+		}
+		return nil, err
+	}
+
+	return room, nil
+}
+
+func (a roomAccess) findMember(roomID, userID string) (*models.RoomMember, error) {
+	member, err := a.roomRepo.FindMember(roomID, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewForbiddenError("You are not a member of this room")
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// memberPermissions ensures the room exists and the user is a member, returning their effective permissions
+func (a roomAccess) memberPermissions(roomID, userID string) (*models.RoomMember, models.PermissionSet, error) {
+	member, permissions, err := a.roomRepo.GetMemberPermissions(roomID, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			// Distinguish a missing room from a missing membership
+			if _, err := a.findRoom(roomID); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, errors.NewForbiddenError("You are not a member of this room")
+		}
+		return nil, nil, err
+	}
+	return member, permissions, nil
+}
+
+// isArchived reports whether the room, or the space containing it, has been archived
+func (a roomAccess) isArchived(room *models.Room) (bool, error) {
+	if room.IsArchived() {
+		return true, nil
+	}
+	if room.ParentSpaceID == nil {
+		return false, nil
+	}
+
+	space, err := a.roomRepo.FindByID(*room.ParentSpaceID)
+	if err != nil {
+		return false, err
+	}
+	return space.IsArchived(), nil
+}
+
+// requirePermission ensures the user is a member of the room holding the given permission
+func (a roomAccess) requirePermission(roomID, userID string, permission models.Permission) (*models.RoomMember, error) {
+	member, permissions, err := a.memberPermissions(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !permissions.Has(permission) {
+		return nil, errors.NewForbiddenError("You do not have permission to perform this action")
+	}
+
+	return member, nil
+}
+
+// requireOwner ensures the user is an owner of the room
+func (a roomAccess) requireOwner(roomID, userID string) (*models.RoomMember, error) {
+	if _, err := a.findRoom(roomID); err != nil {
+		return nil, err
+	}
+
+	member, err := a.findMember(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.Role != models.RoleOwner {
+		return nil, errors.NewForbiddenError("Only room owners can perform this action")
+	}
+
+	return member, nil
+}
+
+// outranks reports whether an actor holding manage_members may act on the target member.
+// A higher fixed role always wins; between equal fixed roles (other than owners), members
+// whose custom role grants manage_members rank above those without it.
+func (a roomAccess) outranks(roomID string, actor, target *models.RoomMember) (bool, error) {
+	if roleRank(actor.Role) != roleRank(target.Role) {
+		return roleRank(actor.Role) > roleRank(target.Role), nil
+	}
+
+	if actor.Role == models.RoleOwner {
+		return false, nil
+	}
+
+	_, targetPermissions, err := a.roomRepo.GetMemberPermissions(roomID, target.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	return !targetPermissions.Has(models.PermissionManageMembers), nil
+}
+
+// roleRank orders room roles so they can be compared
+func roleRank(role string) int {
+	switch role {
+	case models.RoleOwner:
+		return 3
+	case models.RoleAdmin:
+		return 2
+	case models.RoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/types"
+	"converse/internal/utils"
+	"converse/internal/websocket"
+	"converse/pkg/errors"
+	"time"
+)
+
+// RoomInviteLinkService handles shareable room invite links
+type RoomInviteLinkService struct {
+	roomAccess
+	inviteLinkRepo *repositories.RoomInviteLinkRepository
+	notifier       membershipNotifier
+}
+
+// NewRoomInviteLinkService creates a new room invite link service
+func NewRoomInviteLinkService(hub *websocket.Hub) *RoomInviteLinkService {
+	return &RoomInviteLinkService{
+		roomAccess:     roomAccess{roomRepo: repositories.NewRoomRepository()},
+		inviteLinkRepo: repositories.NewRoomInviteLinkRepository(),
+		notifier:       newMembershipNotifier(hub),
+	}
+}
+
+// CreateInviteLink generates a new invite code for the room; requires the invite_members permission
+func (s *RoomInviteLinkService) CreateInviteLink(roomID, userID string, req types.CreateInviteLinkRequest) (*models.RoomInviteLink, error) {
+	if _, err := s.requirePermission(roomID, userID, models.PermissionInviteMembers); err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.NewBadRequestError("Invalid expiry time", "expires_at must be in the future")
+	}
+
+	code, err := utils.GenerateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.RoomInviteLink{
+		RoomID:    roomID,
+		Code:      code,
+		CreatedBy: userID,
+		ExpiresAt: req.ExpiresAt,
+		MaxUses:   req.MaxUses,
+	}
+	if err := s.inviteLinkRepo.Create(link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// GetActiveInviteLinks lists the room's usable invite links; requires the manage_members permission
+func (s *RoomInviteLinkService) GetActiveInviteLinks(roomID, userID string) ([]*models.RoomInviteLink, error) {
+	if _, err := s.requirePermission(roomID, userID, models.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
+	return s.inviteLinkRepo.GetActiveForRoom(roomID)
+}
+
+// RevokeInviteLink disables an invite link; requires the manage_members permission
+func (s *RoomInviteLinkService) RevokeInviteLink(roomID, inviteLinkID, userID string) error {
+	if _, err := s.requirePermission(roomID, userID, models.PermissionManageMembers); err != nil {
+		return err
+	}
+
+	return s.inviteLinkRepo.Revoke(roomID, inviteLinkID)
+}
+
+// RedeemInviteLink adds the user to the room the code belongs to
+func (s *RoomInviteLinkService) RedeemInviteLink(code, userID string) (*models.Room, error) {
+	link, err := s.inviteLinkRepo.Redeem(code, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier.notify(link.RoomID, userID, link.CreatedBy, websocket.MessageTypeUserJoined, websocket.MembershipReasonInviteLink)
+
+	return s.findRoom(link.RoomID)
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/websocket"
+	"converse/pkg/errors"
+	stderrors "errors"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// RoomMemberService handles room membership, invitations and member roles
+type RoomMemberService struct {
+	roomAccess
+	roomRepo       *repositories.RoomRepository
+	invitationRepo *repositories.RoomInvitationRepository
+	moderationRepo *repositories.RoomModerationRepository
+	userRepo       *repositories.UserRepository
+	notifier       membershipNotifier
+}
+
+// NewRoomMemberService creates a new room member service
+func NewRoomMemberService(hub *websocket.Hub) *RoomMemberService {
+	roomRepo := repositories.NewRoomRepository()
+	return &RoomMemberService{
+		roomAccess:     roomAccess{roomRepo: roomRepo},
+		roomRepo:       roomRepo,
+		invitationRepo: repositories.NewRoomInvitationRepository(),
+		moderationRepo: repositories.NewRoomModerationRepository(),
+		userRepo:       repositories.NewUserRepository(),
+		notifier:       newMembershipNotifier(hub),
+	}
+}
+
+// GetMembers lists the direct members of a room visible to the user
+func (s *RoomMemberService) GetMembers(roomID, userID string) ([]*models.RoomMemberWithUser, error) {
+	if _, err := s.findVisibleRoom(roomID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.roomRepo.GetMembers(roomID)
+}
+
+// AddMember adds a user directly to the room; requires the manage_members permission
+func (s *RoomMemberService) AddMember(roomID, actorID, username string) error {
+	if _, err := s.requirePermission(roomID, actorID, models.PermissionManageMembers); err != nil {
+		return err
+	}
+
+	user, err := s.findUserByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addMember(roomID, user.UserID); err != nil {
+		return err
+	}
+
+	s.notifier.notify(roomID, user.UserID, actorID, websocket.MessageTypeUserJoined, websocket.MembershipReasonAdded)
+	return nil
+}
+
+// RemoveMember kicks a member out of the room; requires the manage_members permission and a role above the target's
+func (s *RoomMemberService) RemoveMember(roomID, actorID, targetID string) error {
+	if actorID == targetID {
+		return errors.NewBadRequestError("Cannot remove yourself", "Use the leave endpoint instead")
+	}
+
+	actor, err := s.requirePermission(roomID, actorID, models.PermissionManageMembers)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.findTarget(roomID, targetID)
+	if err != nil {
+		return err
+	}
+
+	outranks, err := s.outranks(roomID, actor, target)
+	if err != nil {
+		return err
+	}
+	if !outranks {
+		return errors.NewForbiddenError("You cannot remove a member with an equal or higher role")
+	}
+
+	if err := s.roomRepo.RemoveMember(roomID, targetID); err != nil {
+		return err
+	}
+
+	s.notifier.notify(roomID, targetID, actorID, websocket.MessageTypeUserLeft, websocket.MembershipReasonKick)
+	return nil
+}
+
+// PromoteMember raises a member one role (member -> admin -> owner).
+// Requires the manage_members permission and a role above the one being granted, unless the actor is an owner.
+func (s *RoomMemberService) PromoteMember(roomID, actorID, targetID string) (*models.RoomMember, error) {
+	actor, err := s.requirePermission(roomID, actorID, models.PermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.findTarget(roomID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var newRole string
+	switch target.Role {
+	case models.RoleMember:
+		newRole = models.RoleAdmin
+	case models.RoleAdmin:
+		newRole = models.RoleOwner
+	default:
+		return nil, errors.NewBadRequestError("Cannot promote member", "Member already has the highest role")
+	}
+
+	if actor.Role != models.RoleOwner && roleRank(actor.Role) <= roleRank(newRole) {
+		return nil, errors.NewForbiddenError("You cannot grant a role equal to or higher than your own")
+	}
+
+	if err := s.roomRepo.UpdateMemberRole(roomID, targetID, newRole); err != nil {
+		return nil, err
+	}
+
+	target.Role = newRole
+	return target, nil
+}
+
+// DemoteMember lowers a member one role (owner -> admin -> member).
+// Requires the manage_members permission and a role above the target's, unless the actor is an owner.
+func (s *RoomMemberService) DemoteMember(roomID, actorID, targetID string) (*models.RoomMember, error) {
+	actor, err := s.requirePermission(roomID, actorID, models.PermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.findTarget(roomID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor.Role != models.RoleOwner {
+		outranks, err := s.outranks(roomID, actor, target)
+		if err != nil {
+			return nil, err
+		}
+		if !outranks {
+			return nil, errors.NewForbiddenError("You cannot demote a member with an equal or higher role")
+		}
+	}
+
+	var newRole string
+	switch target.Role {
+	case models.RoleOwner:
+		if err := s.ensureNotLastOwner(roomID); err != nil {
+			return nil, err
+		}
+		newRole = models.RoleAdmin
+	case models.RoleAdmin:
+		newRole = models.RoleMember
+	default:
+		return nil, errors.NewBadRequestError("Cannot demote member", "Member already has the lowest role")
+	}
+
+	if err := s.roomRepo.UpdateMemberRole(roomID, targetID, newRole); err != nil {
+		return nil, err
+	}
+
+	target.Role = newRole
+	return target, nil
+}
+
+// JoinRoom adds the user to a public room or space
+func (s *RoomMemberService) JoinRoom(roomID, userID string) error {
+	room, err := s.findRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	if room.IsPrivate {
+		return errors.NewForbiddenError("This room is private and requires an invitation")
+	}
+
+	if room.ParentSpaceID != nil {
+		return errors.NewBadRequestError("Cannot join channel directly", "Join the channel's space to access its public channels")
+	}
+
+	if err := s.addMember(roomID, userID); err != nil {
+		return err
+	}
+
+	s.notifier.notify(roomID, userID, userID, websocket.MessageTypeUserJoined, websocket.MembershipReasonJoin)
+	return nil
+}
+
+// LeaveRoom removes the user from the room; the last owner must transfer ownership first
+func (s *RoomMemberService) LeaveRoom(roomID, userID string) error {
+	if _, err := s.findRoom(roomID); err != nil {
+		return err
+	}
+
+	member, err := s.findMember(roomID, userID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == models.RoleOwner {
+		if err := s.ensureNotLastOwner(roomID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.roomRepo.RemoveMember(roomID, userID); err != nil {
+		return err
+	}
+
+	s.notifier.notify(roomID, userID, userID, websocket.MessageTypeUserLeft, websocket.MembershipReasonLeave)
+	return nil
+}
+
+// InviteUser sends a room invitation; requires the invite_members permission
+func (s *RoomMemberService) InviteUser(roomID, inviterID, username string) (*models.RoomInvitation, error) {
+	if _, err := s.requirePermission(roomID, inviterID, models.PermissionInviteMembers); err != nil {
+		return nil, err
+	}
+
+	invitee, err := s.findUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.roomRepo.FindMember(roomID, invitee.UserID); err == nil {
+		return nil, errors.NewConflictError("User is already a member of this room")
+	} else if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.ensureNotBanned(roomID, invitee.UserID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.invitationRepo.FindPending(roomID, invitee.UserID); err == nil {
+		return nil, errors.NewConflictError("An invitation is already pending for this user")
+	} else if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	invitation := &models.RoomInvitation{
+		RoomID:    roomID,
+		InviterID: inviterID,
+		InviteeID: invitee.UserID,
+		Status:    "pending",
+	}
+	if err := s.invitationRepo.Create(invitation); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// GetPendingInvitations lists the room invitations waiting on the user
+func (s *RoomMemberService) GetPendingInvitations(userID string) ([]*models.RoomInvitationWithRoom, error) {
+	return s.invitationRepo.GetPendingForUser(userID)
+}
+
+// AcceptInvitation joins the user to the invitation's room
+func (s *RoomMemberService) AcceptInvitation(invitationID, userID string) (*models.RoomInvitation, error) {
+	invitation, err := s.invitationRepo.Accept(invitationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier.notify(invitation.RoomID, userID, invitation.InviterID, websocket.MessageTypeUserJoined, websocket.MembershipReasonInvite)
+	return invitation, nil
+}
+
+// DeclineInvitation declines a pending invitation addressed to the user
+func (s *RoomMemberService) DeclineInvitation(invitationID, userID string) error {
+	return s.invitationRepo.Decline(invitationID, userID)
+}
+
+func (s *RoomMemberService) addMember(roomID, userID string) error {
+	if err := s.ensureNotBanned(roomID, userID); err != nil {
+		return err
+	}
+
+	if _, err := s.roomRepo.FindMember(roomID, userID); err == nil {
+		return errors.NewConflictError("User is already a member of this room")
+	} else if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	member := &models.RoomMember{
+		RoomID: roomID,
+		UserID: userID,
+		Role:   models.RoleMember,
+	}
+	return s.roomRepo.AddMember(member)
+}
+
+func (s *RoomMemberService) findTarget(roomID, userID string) (*models.RoomMember, error) {
+	member, err := s.roomRepo.FindMember(roomID, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Member not found")
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *RoomMemberService) findUserByUsername(username string) (*models.User, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errors.AppError{
+				Code:    http.StatusBadRequest,
+				Message: "User not found",
+				Details: "No user exists with the provided username",
+			}
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// ensureNotBanned rejects users with an active ban in the room
+func (s *RoomMemberService) ensureNotBanned(roomID, userID string) error {
+	banned, err := s.moderationRepo.IsBanned(roomID, userID)
+	if err != nil {
+		return err
+	}
+
+	if banned {
+		return errors.NewForbiddenError("User is banned from this room")
+	}
+
+	return nil
+}
+
+// ensureNotLastOwner rejects changes that would leave the room without an owner
+func (s *RoomMemberService) ensureNotLastOwner(roomID string) error {
+	owners, err := s.roomRepo.CountMembersWithRole(roomID, models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if owners <= 1 {
+		return errors.NewBadRequestError(
+			"Room must keep an owner",
+			"Transfer ownership to another member before stepping down",
+		)
+	}
+
+	return nil
+}
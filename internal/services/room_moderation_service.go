@@ -0,0 +1,167 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"converse/pkg/errors"
+	stderrors "errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoomModerationService handles room bans and mutes
+type RoomModerationService struct {
+	roomAccess
+	roomRepo       *repositories.RoomRepository
+	moderationRepo *repositories.RoomModerationRepository
+	userRepo       *repositories.UserRepository
+	notifier       membershipNotifier
+}
+
+// NewRoomModerationService creates a new room moderation service
+func NewRoomModerationService(hub *websocket.Hub) *RoomModerationService {
+	roomRepo := repositories.NewRoomRepository()
+	return &RoomModerationService{
+		roomAccess:     roomAccess{roomRepo: roomRepo},
+		roomRepo:       roomRepo,
+		moderationRepo: repositories.NewRoomModerationRepository(),
+		userRepo:       repositories.NewUserRepository(),
+		notifier:       newMembershipNotifier(hub),
+	}
+}
+
+// BanUser bans a user from the room, removing them if they are a member. Space members lose
+// access to the room's channel too, although their space membership is kept.
+// Without a duration the ban lasts until it is lifted manually.
+func (s *RoomModerationService) BanUser(roomID, actorID string, req types.BanUserRequest) (*models.RoomBan, error) {
+	wasMember, err := s.checkModerationTarget(roomID, actorID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	ban := &models.RoomBan{
+		RoomID:   roomID,
+		UserID:   req.UserID,
+		BannedBy: actorID,
+		Reason:   req.Reason,
+	}
+	if req.DurationSeconds != nil {
+		expiresAt := time.Now().Add(time.Duration(*req.DurationSeconds) * time.Second)
+		ban.ExpiresAt = &expiresAt
+	}
+
+	if err := s.moderationRepo.Ban(ban); err != nil {
+		return nil, err
+	}
+
+	if wasMember {
+		s.notifier.notify(roomID, req.UserID, actorID, websocket.MessageTypeUserLeft, websocket.MembershipReasonBan)
+	}
+
+	return ban, nil
+}
+
+// UnbanUser lifts an active ban; requires the manage_members permission
+func (s *RoomModerationService) UnbanUser(roomID, actorID, targetID string) error {
+	if _, err := s.requirePermission(roomID, actorID, models.PermissionManageMembers); err != nil {
+		return err
+	}
+
+	return s.moderationRepo.Unban(roomID, targetID)
+}
+
+// GetBans lists the room's active bans; requires the manage_members permission
+func (s *RoomModerationService) GetBans(roomID, actorID string) ([]*models.RoomBan, error) {
+	if _, err := s.requirePermission(roomID, actorID, models.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
+	return s.moderationRepo.GetActiveBans(roomID)
+}
+
+// MuteUser stops a member from sending messages in the room for the given duration. Space members
+// can be muted in a public channel they reach through the space; a mute in a space applies to
+// all of its channels.
+func (s *RoomModerationService) MuteUser(roomID, actorID string, req types.MuteUserRequest) (*models.RoomMute, error) {
+	isMember, err := s.checkModerationTarget(roomID, actorID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isMember {
+		return nil, errors.NewNotFoundError("Member not found")
+	}
+
+	mute := &models.RoomMute{
+		RoomID:    roomID,
+		UserID:    req.UserID,
+		MutedBy:   actorID,
+		Reason:    req.Reason,
+		ExpiresAt: time.Now().Add(time.Duration(req.DurationSeconds) * time.Second),
+	}
+
+	if err := s.moderationRepo.Mute(mute); err != nil {
+		return nil, err
+	}
+
+	return mute, nil
+}
+
+// UnmuteUser lifts an active mute; requires the manage_members permission
+func (s *RoomModerationService) UnmuteUser(roomID, actorID, targetID string) error {
+	if _, err := s.requirePermission(roomID, actorID, models.PermissionManageMembers); err != nil {
+		return err
+	}
+
+	return s.moderationRepo.Unmute(roomID, targetID)
+}
+
+// GetMutes lists the room's active mutes; requires the manage_members permission
+func (s *RoomModerationService) GetMutes(roomID, actorID string) ([]*models.RoomMute, error) {
+	if _, err := s.requirePermission(roomID, actorID, models.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
+	return s.moderationRepo.GetActiveMutes(roomID)
+}
+
+// checkModerationTarget ensures the actor may manage members and outranks the target,
+// and reports whether the target is currently a member of the room, directly or through its space
+func (s *RoomModerationService) checkModerationTarget(roomID, actorID, targetID string) (bool, error) {
+	if actorID == targetID {
+		return false, errors.NewBadRequestError("Invalid target", "You cannot moderate yourself")
+	}
+
+	actor, err := s.requirePermission(roomID, actorID, models.PermissionManageMembers)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := s.userRepo.FindByID(targetID); err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return false, errors.NewNotFoundError("User not found")
+		}
+		return false, err
+	}
+
+	target, _, err := s.roomRepo.GetMemberPermissions(roomID, targetID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	outranks, err := s.outranks(roomID, actor, target)
+	if err != nil {
+		return false, err
+	}
+	if !outranks {
+		return false, errors.NewForbiddenError("You cannot moderate a member with an equal or higher role")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/types"
+	"converse/pkg/errors"
+	stderrors "errors"
+
+	"gorm.io/gorm"
+)
+
+// RoomRoleService handles custom per-room roles and their permissions
+type RoomRoleService struct {
+	roomAccess
+	roomRepo *repositories.RoomRepository
+	roleRepo *repositories.RoomRoleRepository
+}
+
+// NewRoomRoleService creates a new room role service
+func NewRoomRoleService() *RoomRoleService {
+	roomRepo := repositories.NewRoomRepository()
+	return &RoomRoleService{
+		roomAccess: roomAccess{roomRepo: roomRepo},
+		roomRepo:   roomRepo,
+		roleRepo:   repositories.NewRoomRoleRepository(),
+	}
+}
+
+// GetRoles lists the room's custom roles; any member may view them
+func (s *RoomRoleService) GetRoles(roomID, userID string) ([]*models.RoomRole, error) {
+	if _, _, err := s.memberPermissions(roomID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.roleRepo.GetForRoom(roomID)
+}
+
+// GetMyPermissions returns the caller's effective permissions in the room
+func (s *RoomRoleService) GetMyPermissions(roomID, userID string) (models.PermissionSet, error) {
+	_, permissions, err := s.memberPermissions(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// CreateRole creates a custom role; requires the manage_room permission
+func (s *RoomRoleService) CreateRole(roomID, userID string, req types.CreateRoomRoleRequest) (*models.RoomRole, error) {
+	permissions, err := s.checkGrantablePermissions(roomID, userID, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.RoomRole{
+		RoomID:      roomID,
+		Name:        req.Name,
+		Permissions: permissions,
+	}
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// UpdateRole renames a custom role or replaces its permissions; requires the manage_room permission
+func (s *RoomRoleService) UpdateRole(roomID, roleID, userID string, req types.UpdateRoomRoleRequest) (*models.RoomRole, error) {
+	var requested []string
+	if req.Permissions != nil {
+		requested = *req.Permissions
+	}
+
+	permissions, err := s.checkGrantablePermissions(roomID, userID, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.findRole(roomID, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		role.Name = *req.Name
+	}
+	if req.Permissions != nil {
+		role.Permissions = permissions
+	}
+
+	if err := s.roleRepo.Update(role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// DeleteRole removes a custom role from the room and from every member holding it
+func (s *RoomRoleService) DeleteRole(roomID, roleID, userID string) error {
+	if _, err := s.requirePermission(roomID, userID, models.PermissionManageRoom); err != nil {
+		return err
+	}
+
+	if _, err := s.findRole(roomID, roleID); err != nil {
+		return err
+	}
+
+	return s.roleRepo.Delete(roomID, roleID)
+}
+
+// AssignRole sets or clears a member's custom role. Requires the manage_members permission,
+// a rank above the target, and every permission the role would grant.
+func (s *RoomRoleService) AssignRole(roomID, actorID, targetID string, req types.AssignRoomRoleRequest) error {
+	actor, actorPermissions, err := s.memberPermissions(roomID, actorID)
+	if err != nil {
+		return err
+	}
+
+	if !actorPermissions.Has(models.PermissionManageMembers) {
+		return errors.NewForbiddenError("You do not have permission to perform this action")
+	}
+
+	target, err := s.roomRepo.FindMember(roomID, targetID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFoundError("Member not found")
+		}
+		return err
+	}
+
+	if actor.Role != models.RoleOwner {
+		outranks, err := s.outranks(roomID, actor, target)
+		if err != nil {
+			return err
+		}
+		if !outranks {
+			return errors.NewForbiddenError("You cannot change the role of a member with an equal or higher role")
+		}
+	}
+
+	if req.RoleID != nil {
+		role, err := s.findRole(roomID, *req.RoleID)
+		if err != nil {
+			return err
+		}
+
+		if actor.Role != models.RoleOwner {
+			for _, permission := range role.Permissions {
+				if !actorPermissions.Has(permission) {
+					return errors.NewForbiddenError("You cannot assign a role with permissions you do not have")
+				}
+			}
+		}
+	}
+
+	return s.roleRepo.AssignToMember(roomID, targetID, req.RoleID)
+}
+
+// checkGrantablePermissions requires manage_room and validates the requested permissions,
+// rejecting any the actor does not hold themselves unless they are an owner
+func (s *RoomRoleService) checkGrantablePermissions(roomID, userID string, requested []string) (models.PermissionSet, error) {
+	actor, actorPermissions, err := s.memberPermissions(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !actorPermissions.Has(models.PermissionManageRoom) {
+		return nil, errors.NewForbiddenError("You do not have permission to perform this action")
+	}
+
+	permissions := make(models.PermissionSet, 0, len(requested))
+	for _, name := range requested {
+		permission := models.Permission(name)
+		if !permissions.Has(permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	if err := permissions.Validate(); err != nil {
+		return nil, errors.NewBadRequestError("Invalid permissions", err.Error())
+	}
+
+	if actor.Role != models.RoleOwner {
+		for _, permission := range permissions {
+			if !actorPermissions.Has(permission) {
+				return nil, errors.NewForbiddenError("You cannot grant permissions you do not have")
+			}
+		}
+	}
+
+	return permissions, nil
+}
+
+func (s *RoomRoleService) findRole(roomID, roleID string) (*models.RoomRole, error) {
+	role, err := s.roleRepo.FindByID(roomID, roleID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Role not found")
+		}
+		return nil, err
+	}
+	return role, nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/types"
+	"converse/pkg/errors"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoomService handles business logic for rooms
+type RoomService struct {
+	roomAccess
+	roomRepo *repositories.RoomRepository
+}
+
+// NewRoomService creates a new room service
+func NewRoomService() *RoomService {
+	roomRepo := repositories.NewRoomRepository()
+	return &RoomService{
+		roomAccess: roomAccess{roomRepo: roomRepo},
+		roomRepo:   roomRepo,
+	}
+}
+
+// CreateRoom creates a room owned by the given user
+func (s *RoomService) CreateRoom(req types.CreateRoomRequest, userID string) (*models.Room, error) {
+	room := &models.Room{
+		Name:        req.Name,
+		Description: req.Description,
+		IsPrivate:   req.IsPrivate,
+		CreatedBy:   userID,
+	}
+
+	if err := s.roomRepo.Create(room); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// GetRoom returns a room if it is visible to the user
+func (s *RoomService) GetRoom(roomID, userID string) (*models.Room, error) {
+	return s.findVisibleRoom(roomID, userID)
+}
+
+// GetUserRooms returns the rooms the user belongs to, by name or by latest activity
+func (s *RoomService) GetUserRooms(userID, sort string) ([]*models.Room, error) {
+	switch sort {
+	case "":
+		sort = repositories.RoomSortName
+	case repositories.RoomSortName, repositories.RoomSortActivity:
+	default:
+		return nil, errors.NewBadRequestError("Invalid sort order", "sort must be one of name or activity")
+	}
+
+	return s.roomRepo.GetRoomsForUser(userID, sort)
+}
+
+// PaginatedRooms represents a paginated page of the public room directory
+type PaginatedRooms struct {
+	Rooms       []*models.RoomDirectoryEntry `json:"rooms"`
+	CurrentPage int                          `json:"current_page"`
+	PageSize    int                          `json:"page_size"`
+	HasMore     bool                         `json:"has_more"`
+}
+
+// GetDirectory searches the public room directory
+func (s *RoomService) GetDirectory(userID, query, sort string, page, pageSize int) (*PaginatedRooms, error) {
+	switch sort {
+	case "":
+		sort = repositories.RoomSortMembers
+	case repositories.RoomSortName, repositories.RoomSortMembers, repositories.RoomSortActivity:
+	default:
+		return nil, errors.NewBadRequestError("Invalid sort order", "sort must be one of name, members or activity")
+	}
+
+	offset := (page - 1) * pageSize
+
+	// Get one more room than requested to determine if there are more pages
+	rooms, err := s.roomRepo.SearchPublicRooms(userID, strings.TrimSpace(query), sort, pageSize+1, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := false
+	if len(rooms) > pageSize {
+		hasMore = true
+		rooms = rooms[:pageSize]
+	}
+
+	return &PaginatedRooms{
+		Rooms:       rooms,
+		CurrentPage: page,
+		PageSize:    pageSize,
+		HasMore:     hasMore,
+	}, nil
+}
+
+// UpdateRoom applies the requested changes; requires the manage_room permission
+func (s *RoomService) UpdateRoom(roomID, userID string, req types.UpdateRoomRequest) (*models.Room, error) {
+	if _, err := s.requirePermission(roomID, userID, models.PermissionManageRoom); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]any{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.IsPrivate != nil {
+		updates["is_private"] = *req.IsPrivate
+	}
+	if req.AnnouncementOnly != nil {
+		updates["announcement_only"] = *req.AnnouncementOnly
+	}
+	if req.SlowModeSeconds != nil {
+		updates["slow_mode_seconds"] = *req.SlowModeSeconds
+	}
+
+	if len(updates) > 0 {
+		if err := s.roomRepo.Update(roomID, updates); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.findRoom(roomID)
+}
+
+// TransferOwnership hands the room to another member; the current owner becomes an admin
+func (s *RoomService) TransferOwnership(roomID, userID string, req types.TransferOwnershipRequest) error {
+	if req.UserID == userID {
+		return errors.NewBadRequestError("Invalid target", "You already own this room")
+	}
+
+	if _, err := s.requireOwner(roomID, userID); err != nil {
+		return err
+	}
+
+	target, err := s.roomRepo.FindMember(roomID, req.UserID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFoundError("Member not found")
+		}
+		return err
+	}
+
+	if target.Role == models.RoleOwner {
+		return errors.NewBadRequestError("Invalid target", "Member is already an owner")
+	}
+
+	return s.roomRepo.TransferOwnership(roomID, userID, req.UserID)
+}
+
+// ArchiveRoom makes the room read-only; requires the manage_room permission
+func (s *RoomService) ArchiveRoom(roomID, userID string) (*models.Room, error) {
+	if _, err := s.requirePermission(roomID, userID, models.PermissionManageRoom); err != nil {
+		return nil, err
+	}
+
+	room, err := s.findRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room.IsArchived() {
+		return nil, errors.NewConflictError("Room is already archived")
+	}
+
+	if err := s.roomRepo.Update(roomID, map[string]any{"archived_at": time.Now()}); err != nil {
+		return nil, err
+	}
+
+	return s.findRoom(roomID)
+}
+
+// UnarchiveRoom makes an archived room writable again; requires the manage_room permission
+func (s *RoomService) UnarchiveRoom(roomID, userID string) (*models.Room, error) {
+	if _, err := s.requirePermission(roomID, userID, models.PermissionManageRoom); err != nil {
+		return nil, err
+	}
+
+	room, err := s.findRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !room.IsArchived() {
+		return nil, errors.NewConflictError("Room is not archived")
+	}
+
+	if err := s.roomRepo.Update(roomID, map[string]any{"archived_at": nil}); err != nil {
+		return nil, err
+	}
+
+	return s.findRoom(roomID)
+}
+
+// DeleteRoom permanently deletes a room and everything in it, including uploaded files;
+// only owners may delete a room
+func (s *RoomService) DeleteRoom(roomID, userID string) error {
+	if _, err := s.requireOwner(roomID, userID); err != nil {
+		return err
+	}
+
+	attachmentKeys, err := s.roomRepo.Delete(roomID)
+	if err != nil {
+		return err
+	}
+
+	deleteObjects(attachmentKeys)
+	return nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/websocket"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// schedulerInterval is how often the scheduler looks for scheduled messages that are due
+const schedulerInterval = 5 * time.Second
+
+// schedulerBatchSize bounds how many due messages are loaded at a time
+const schedulerBatchSize = 100
+
+// RunScheduler sends scheduled messages once they are due. Messages that came due while the
+// server was down are sent as soon as it starts.
+func (s *ScheduledMessageService) RunScheduler() {
+	s.recoverScheduledMessages()
+
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDueMessages()
+		<-ticker.C
+	}
+}
+
+// recoverScheduledMessages settles messages a previous run claimed but may not have finished
+// sending. Sent messages share the scheduled message's ID, so any that were stored are dropped
+// and the rest go back to pending.
+func (s *ScheduledMessageService) recoverScheduledMessages() {
+	claimed, err := s.scheduledRepo.FindClaimed()
+	if err != nil {
+		log.Printf("Error loading claimed scheduled messages: %v", err)
+		return
+	}
+
+	for _, scheduled := range claimed {
+		_, err := s.messageRepo.FindByID(scheduled.ScheduledMessageID)
+		switch {
+		case err == nil:
+			err = s.scheduledRepo.Delete(scheduled.ScheduledMessageID)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			err = s.scheduledRepo.Release(scheduled.ScheduledMessageID, scheduled.SendAt)
+		}
+		if err != nil {
+			log.Printf("Error recovering scheduled message %s: %v", scheduled.ScheduledMessageID, err)
+		}
+	}
+}
+
+// dispatchDueMessages sends every message that is due, a batch at a time, until the database
+// stops answering. Messages put back for a retry are due again later, so they are not reloaded.
+func (s *ScheduledMessageService) dispatchDueMessages() {
+	for {
+		due, err := s.scheduledRepo.FindDue(time.Now(), schedulerBatchSize)
+		if err != nil {
+			log.Printf("Error loading due scheduled messages: %v", err)
+			return
+		}
+
+		for _, scheduled := range due {
+			if !s.dispatchScheduledMessage(scheduled) {
+				return
+			}
+		}
+
+		if len(due) < schedulerBatchSize {
+			return
+		}
+	}
+}
+
+// dispatchScheduledMessage claims and sends one scheduled message as a new message from its sender.
+// The row loaded by FindDue may be stale, so what is sent is the row as it was claimed.
+// Slow mode and unexpected errors put it back to be retried later; other rejections mark it failed
+// and tell the sender why. It returns false if the message could not be claimed.
+func (s *ScheduledMessageService) dispatchScheduledMessage(due *models.ScheduledMessage) bool {
+	scheduled, err := s.scheduledRepo.Claim(due.ScheduledMessageID, time.Now())
+	if err != nil {
+		log.Printf("Error claiming scheduled message %s: %v", due.ScheduledMessageID, err)
+		return false
+	}
+	if scheduled == nil {
+		// Cancelled, rescheduled or taken by another server in the meantime
+		return true
+	}
+
+	incomingMsg := websocket.IncomingMessage{
+		Type:             websocket.MessageTypeNewMessage,
+		MessageID:        scheduled.ScheduledMessageID,
+		RoomID:           scheduled.RoomID,
+		ThreadID:         scheduled.ThreadID,
+		Content:          scheduled.Content,
+		ReplyToMessageID: scheduled.ReplyToMessageID,
+		ParentMessageID:  scheduled.ParentMessageID,
+		AlsoSendToRoom:   scheduled.AlsoSendToRoom,
+	}
+
+	_, err = s.messageService.SendMessage(scheduled.SenderID, incomingMsg, nil)
+	if err == nil {
+		if err := s.scheduledRepo.Delete(scheduled.ScheduledMessageID); err != nil {
+			log.Printf("Error removing sent scheduled message %s: %v", scheduled.ScheduledMessageID, err)
+		}
+		return true
+	}
+
+	var rejection *websocket.SendRejection
+	if !errors.As(err, &rejection) || rejection.RetryAfter > 0 {
+		retryAt := time.Now().Add(schedulerInterval)
+		if rejection != nil {
+			retryAt = time.Now().Add(time.Duration(rejection.RetryAfter) * time.Second)
+		} else {
+			log.Printf("Error sending scheduled message %s: %v", scheduled.ScheduledMessageID, err)
+		}
+		if err := s.scheduledRepo.Release(scheduled.ScheduledMessageID, retryAt); err != nil {
+			log.Printf("Error releasing scheduled message %s: %v", scheduled.ScheduledMessageID, err)
+		}
+		return true
+	}
+
+	if err := s.scheduledRepo.MarkFailed(scheduled.ScheduledMessageID, rejection.Reason); err != nil {
+		log.Printf("Error marking scheduled message %s failed: %v", scheduled.ScheduledMessageID, err)
+	}
+	s.hub.SendScheduledFailure(scheduled, rejection.Reason)
+	return true
+}
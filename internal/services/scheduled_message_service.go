@@ -0,0 +1,177 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/types"
+	"converse/internal/websocket"
+	"converse/pkg/errors"
+	stderrors "errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxScheduleAhead is how far in the future a message can be scheduled
+const MaxScheduleAhead = 365 * 24 * time.Hour
+
+// ScheduledMessageService handles messages written now to be sent later. Its scheduler
+// sends them once due, applying the same checks as any other message at that point.
+type ScheduledMessageService struct {
+	roomAccess
+	dmRepo         *repositories.DirectMessageRepository
+	scheduledRepo  *repositories.ScheduledMessageRepository
+	messageRepo    *repositories.MessageRepository
+	messageService *MessageService
+	hub            *websocket.Hub
+}
+
+// NewScheduledMessageService creates a new scheduled message service
+func NewScheduledMessageService(hub *websocket.Hub) *ScheduledMessageService {
+	return &ScheduledMessageService{
+		roomAccess:     roomAccess{roomRepo: repositories.NewRoomRepository()},
+		dmRepo:         repositories.NewDirectMessageRepository(),
+		scheduledRepo:  repositories.NewScheduledMessageRepository(),
+		messageRepo:    repositories.NewMessageRepository(),
+		messageService: NewMessageService(hub),
+		hub:            hub,
+	}
+}
+
+// ScheduleMessage schedules a message to a room the user can post in, or to one of their DM threads
+func (s *ScheduledMessageService) ScheduleMessage(userID string, req types.ScheduleMessageRequest) (*models.ScheduledMessage, error) {
+	roomID, threadID := nonEmpty(req.RoomID), nonEmpty(req.ThreadID)
+	if (roomID == nil) == (threadID == nil) {
+		return nil, errors.NewBadRequestError("Missing conversation", "exactly one of room_id or thread_id is required")
+	}
+
+	if err := validateSendAt(req.SendAt); err != nil {
+		return nil, err
+	}
+
+	if roomID != nil {
+		if _, err := s.requirePermission(*roomID, userID, models.PermissionSendMessages); err != nil {
+			return nil, err
+		}
+	} else if err := s.checkParticipant(*threadID, userID); err != nil {
+		return nil, err
+	}
+
+	scheduled := &models.ScheduledMessage{
+		SenderID:         userID,
+		RoomID:           roomID,
+		ThreadID:         threadID,
+		Content:          req.Content,
+		ReplyToMessageID: nonEmpty(req.ReplyToMessageID),
+		ParentMessageID:  nonEmpty(req.ParentMessageID),
+		AlsoSendToRoom:   req.AlsoSendToRoom,
+		SendAt:           req.SendAt,
+		Status:           models.ScheduledStatusPending,
+	}
+	if err := s.scheduledRepo.Create(scheduled); err != nil {
+		return nil, err
+	}
+
+	return scheduled, nil
+}
+
+// GetScheduledMessages lists the user's unsent scheduled messages, optionally for one room or DM thread
+func (s *ScheduledMessageService) GetScheduledMessages(userID, roomID, threadID string) ([]*models.ScheduledMessage, error) {
+	return s.scheduledRepo.GetForSender(userID, roomID, threadID)
+}
+
+// UpdateScheduledMessage changes the content or send time of one of the user's scheduled messages.
+// A failed message goes back to pending, so saving it again retries it.
+func (s *ScheduledMessageService) UpdateScheduledMessage(scheduledMessageID, userID string, req types.UpdateScheduledMessageRequest) (*models.ScheduledMessage, error) {
+	if _, err := s.findOwnScheduledMessage(scheduledMessageID, userID); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]any{
+		"status":         models.ScheduledStatusPending,
+		"failure_reason": nil,
+	}
+	if req.Content != nil {
+		updates["content"] = *req.Content
+	}
+	if req.SendAt != nil {
+		if err := validateSendAt(*req.SendAt); err != nil {
+			return nil, err
+		}
+		updates["send_at"] = *req.SendAt
+	}
+
+	updated, err := s.scheduledRepo.UpdateUnclaimed(scheduledMessageID, updates)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, errors.NewConflictError("This message is already being sent")
+	}
+
+	return s.findOwnScheduledMessage(scheduledMessageID, userID)
+}
+
+// CancelScheduledMessage deletes one of the user's scheduled messages before it is sent
+func (s *ScheduledMessageService) CancelScheduledMessage(scheduledMessageID, userID string) error {
+	if _, err := s.findOwnScheduledMessage(scheduledMessageID, userID); err != nil {
+		return err
+	}
+
+	deleted, err := s.scheduledRepo.DeleteUnclaimed(scheduledMessageID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.NewConflictError("This message is already being sent")
+	}
+
+	return nil
+}
+
+// findOwnScheduledMessage loads a scheduled message, hiding those of other users.
+// Messages that have been sent no longer exist.
+func (s *ScheduledMessageService) findOwnScheduledMessage(scheduledMessageID, userID string) (*models.ScheduledMessage, error) {
+	scheduled, err := s.scheduledRepo.FindByID(scheduledMessageID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Scheduled message not found")
+		}
+		return nil, err
+	}
+
+	if scheduled.SenderID != userID {
+		return nil, errors.NewNotFoundError("Scheduled message not found")
+	}
+
+	return scheduled, nil
+}
+
+// checkParticipant only allows the two participants of a DM thread
+func (s *ScheduledMessageService) checkParticipant(threadID, userID string) error {
+	thread, err := s.dmRepo.FindThreadByID(threadID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFoundError("Thread not found")
+		}
+		return err
+	}
+
+	if thread.User1ID != userID && thread.User2ID != userID {
+		return errors.NewNotFoundError("Thread not found")
+	}
+
+	return nil
+}
+
+// validateSendAt requires a send time in the future and no more than MaxScheduleAhead away
+func validateSendAt(sendAt time.Time) error {
+	now := time.Now()
+	if !sendAt.After(now) {
+		return errors.NewBadRequestError("Invalid send time", "send_at must be in the future")
+	}
+	if sendAt.After(now.Add(MaxScheduleAhead)) {
+		return errors.NewBadRequestError("Invalid send time", "send_at can be at most one year away")
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"converse/internal/models"
+	"converse/internal/repositories"
+	"converse/internal/types"
+	"converse/pkg/errors"
+	stderrors "errors"
+
+	"gorm.io/gorm"
+)
+
+// SpaceService handles spaces and the tree of categories and channels inside them
+type SpaceService struct {
+	roomAccess
+	roomRepo  *repositories.RoomRepository
+	spaceRepo *repositories.SpaceRepository
+}
+
+// NewSpaceService creates a new space service
+func NewSpaceService() *SpaceService {
+	roomRepo := repositories.NewRoomRepository()
+	return &SpaceService{
+		roomAccess: roomAccess{roomRepo: roomRepo},
+		roomRepo:   roomRepo,
+		spaceRepo:  repositories.NewSpaceRepository(),
+	}
+}
+
+// SpaceCategoryNode is a category together with its ordered channels
+type SpaceCategoryNode struct {
+	*models.SpaceCategory
+	Channels []*models.Room `json:"channels"`
+}
+
+// SpaceTree is the channel layout of a space as seen by a given user
+type SpaceTree struct {
+	Space         *models.Room         `json:"space"`
+	Categories    []*SpaceCategoryNode `json:"categories"`
+	Uncategorized []*models.Room       `json:"uncategorized"`
+}
+
+// CreateSpace creates a space owned by the given user
+func (s *SpaceService) CreateSpace(req types.CreateRoomRequest, userID string) (*models.Room, error) {
+	space := &models.Room{
+		Name:        req.Name,
+		Description: req.Description,
+		IsPrivate:   req.IsPrivate,
+		RoomType:    models.RoomTypeSpace,
+		CreatedBy:   userID,
+	}
+
+	if err := s.roomRepo.Create(space); err != nil {
+		return nil, err
+	}
+
+	return space, nil
+}
+
+// GetChannelTree returns the space's categories and the channels the user can see
+func (s *SpaceService) GetChannelTree(spaceID, userID string) (*SpaceTree, error) {
+	space, err := s.findVisibleRoom(spaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !space.IsSpace() {
+		return nil, errors.NewNotFoundError("Space not found")
+	}
+
+	categories, err := s.spaceRepo.GetCategories(spaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := s.spaceRepo.GetVisibleChannels(spaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &SpaceTree{
+		Space:         space,
+		Categories:    make([]*SpaceCategoryNode, 0, len(categories)),
+		Uncategorized: []*models.Room{},
+	}
+
+	nodes := make(map[string]*SpaceCategoryNode, len(categories))
+	for _, category := range categories {
+		node := &SpaceCategoryNode{SpaceCategory: category, Channels: []*models.Room{}}
+		nodes[category.CategoryID] = node
+		tree.Categories = append(tree.Categories, node)
+	}
+
+	// Channels arrive ordered by position, so appending keeps each list ordered
+	for _, channel := range channels {
+		if channel.CategoryID != nil {
+			if node, ok := nodes[*channel.CategoryID]; ok {
+				node.Channels = append(node.Channels, channel)
+				continue
+			}
+		}
+		tree.Uncategorized = append(tree.Uncategorized, channel)
+	}
+
+	return tree, nil
+}
+
+// CreateChannel creates a room inside the space; requires manage_room on the space
+func (s *SpaceService) CreateChannel(spaceID, userID string, req types.CreateChannelRequest) (*models.Room, error) {
+	if err := s.requireSpacePermission(spaceID, userID); err != nil {
+		return nil, err
+	}
+
+	categoryID, err := s.resolveCategoryID(spaceID, req.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := &models.Room{
+		Name:          req.Name,
+		Description:   req.Description,
+		IsPrivate:     req.IsPrivate,
+		ParentSpaceID: &spaceID,
+		CategoryID:    categoryID,
+		Position:      req.Position,
+		CreatedBy:     userID,
+	}
+
+	if err := s.roomRepo.Create(channel); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// MoveChannel changes a channel's category and/or position; requires manage_room on the space
+func (s *SpaceService) MoveChannel(spaceID, roomID, userID string, req types.MoveChannelRequest) (*models.Room, error) {
+	if err := s.requireSpacePermission(spaceID, userID); err != nil {
+		return nil, err
+	}
+
+	channel, err := s.findRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.ParentSpaceID == nil || *channel.ParentSpaceID != spaceID {
+		return nil, errors.NewNotFoundError("Channel not found")
+	}
+
+	updates := map[string]any{}
+	if req.CategoryID != nil {
+		categoryID, err := s.resolveCategoryID(spaceID, req.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		updates["category_id"] = categoryID
+	}
+	if req.Position != nil {
+		updates["position"] = *req.Position
+	}
+
+	if len(updates) > 0 {
+		if err := s.spaceRepo.MoveChannel(spaceID, roomID, updates); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.findRoom(roomID)
+}
+
+// CreateCategory adds a category to the space; requires manage_room on the space
+func (s *SpaceService) CreateCategory(spaceID, userID string, req types.CreateCategoryRequest) (*models.SpaceCategory, error) {
+	if err := s.requireSpacePermission(spaceID, userID); err != nil {
+		return nil, err
+	}
+
+	category := &models.SpaceCategory{
+		SpaceID:  spaceID,
+		Name:     req.Name,
+		Position: req.Position,
+	}
+	if err := s.spaceRepo.CreateCategory(category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// UpdateCategory renames or repositions a category; requires manage_room on the space
+func (s *SpaceService) UpdateCategory(spaceID, categoryID, userID string, req types.UpdateCategoryRequest) (*models.SpaceCategory, error) {
+	if err := s.requireSpacePermission(spaceID, userID); err != nil {
+		return nil, err
+	}
+
+	category, err := s.findCategory(spaceID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.Position != nil {
+		category.Position = *req.Position
+	}
+
+	if err := s.spaceRepo.UpdateCategory(category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// DeleteCategory removes a category, leaving its channels uncategorized; requires manage_room on the space
+func (s *SpaceService) DeleteCategory(spaceID, categoryID, userID string) error {
+	if err := s.requireSpacePermission(spaceID, userID); err != nil {
+		return err
+	}
+
+	if _, err := s.findCategory(spaceID, categoryID); err != nil {
+		return err
+	}
+
+	return s.spaceRepo.DeleteCategory(spaceID, categoryID)
+}
+
+// requireSpacePermission ensures the room is a space and the user may manage it
+func (s *SpaceService) requireSpacePermission(spaceID, userID string) error {
+	space, err := s.findRoom(spaceID)
+	if err != nil {
+		return err
+	}
+	if !space.IsSpace() {
+		return errors.NewNotFoundError("Space not found")
+	}
+
+	_, err = s.requirePermission(spaceID, userID, models.PermissionManageRoom)
+	return err
+}
+
+// resolveCategoryID validates a requested category; nil or empty means uncategorized
+func (s *SpaceService) resolveCategoryID(spaceID string, categoryID *string) (*string, error) {
+	if categoryID == nil || *categoryID == "" {
+		return nil, nil
+	}
+
+	if _, err := s.findCategory(spaceID, *categoryID); err != nil {
+		return nil, err
+	}
+
+	return categoryID, nil
+}
+
+func (s *SpaceService) findCategory(spaceID, categoryID string) (*models.SpaceCategory, error) {
+	category, err := s.spaceRepo.FindCategory(spaceID, categoryID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Category not found")
+		}
+		return nil, err
+	}
+	return category, nil
+}
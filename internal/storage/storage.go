@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"converse/internal/config"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound is returned when an object does not exist in the backend
+var ErrNotFound = errors.New("storage: object not found")
+
+// Object describes a file being stored. SHA256 is the hex digest of the content; backends that
+// sign or verify uploads use it when set.
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	SHA256      string
+}
+
+// Storage is a blob store for uploaded files, addressed by slash-separated keys
+type Storage interface {
+	Put(ctx context.Context, object Object, body io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var store Storage
+
+// Init selects the storage backend named in the config
+func Init(c *config.Config) error {
+	switch c.StorageBackend {
+	case "local":
+		local, err := NewLocalStorage(c.StorageLocalPath)
+		if err != nil {
+			return err
+		}
+		store = local
+	case "s3":
+		s3, err := NewS3Storage(S3Config{
+			Endpoint:        c.S3Endpoint,
+			Region:          c.S3Region,
+			Bucket:          c.S3Bucket,
+			AccessKeyID:     c.S3AccessKeyID,
+			SecretAccessKey: c.S3SecretKey,
+			UsePathStyle:    c.S3UsePathStyle,
+		})
+		if err != nil {
+			return err
+		}
+		store = s3
+	default:
+		return fmt.Errorf("storage: unknown backend %q", c.StorageBackend)
+	}
+	return nil
+}
+
+func GetStorage() Storage {
+	return store
+}
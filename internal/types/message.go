@@ -0,0 +1,57 @@
+package types
+
+import "time"
+
+type EditMessageRequest struct {
+	Content string `json:"content" binding:"required,min=1"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=64"`
+}
+
+// MessagePageQuery selects a window of message history. At most one of Before, After
+// (cursors from a previous page) and Around (a message ID) may be set.
+type MessagePageQuery struct {
+	Before string
+	After  string
+	Around string
+	Limit  int
+}
+
+// SearchMessagesQuery is a full-text search over the caller's rooms and DM threads
+type SearchMessagesQuery struct {
+	Query       string     `form:"q" binding:"required,min=1,max=200"`
+	SenderID    string     `form:"sender_id"`
+	RoomID      string     `form:"room_id"`
+	ThreadID    string     `form:"thread_id"`
+	ContentType string     `form:"content_type"`
+	From        *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To          *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// UploadAttachmentRequest is the multipart form sent alongside the uploaded file
+type UploadAttachmentRequest struct {
+	RoomID           *string `form:"room_id"`
+	ThreadID         *string `form:"thread_id"`
+	ReplyToMessageID *string `form:"reply_to_message_id"`
+	ParentMessageID  *string `form:"parent_message_id"`
+	AlsoSendToRoom   bool    `form:"also_send_to_room"`
+}
+
+// ScheduleMessageRequest is a message to send to a room or DM thread at SendAt
+type ScheduleMessageRequest struct {
+	RoomID           *string   `json:"room_id"`
+	ThreadID         *string   `json:"thread_id"`
+	Content          string    `json:"content" binding:"required,min=1"`
+	SendAt           time.Time `json:"send_at" binding:"required"`
+	ReplyToMessageID *string   `json:"reply_to_message_id"`
+	ParentMessageID  *string   `json:"parent_message_id"`
+	AlsoSendToRoom   bool      `json:"also_send_to_room"`
+}
+
+// UpdateScheduledMessageRequest changes the content or send time of a scheduled message
+type UpdateScheduledMessageRequest struct {
+	Content *string    `json:"content" binding:"omitempty,min=1"`
+	SendAt  *time.Time `json:"send_at"`
+}
@@ -0,0 +1,62 @@
+package types
+
+import "time"
+
+type CreateRoomRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=100"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+}
+
+type UpdateRoomRequest struct {
+	Name             *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description      *string `json:"description"`
+	IsPrivate        *bool   `json:"is_private"`
+	AnnouncementOnly *bool   `json:"announcement_only"`
+	SlowModeSeconds  *int    `json:"slow_mode_seconds" binding:"omitempty,min=0,max=21600"`
+}
+
+type TransferOwnershipRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+type AddRoomMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type InviteToRoomRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type CreateInviteLinkRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	MaxUses   *int       `json:"max_uses" binding:"omitempty,min=1"`
+}
+
+// BanUserRequest bans a user, permanently unless DurationSeconds is given. Timed bans and mutes
+// last at most a year (31536000 seconds), which also keeps the expiry from overflowing.
+type BanUserRequest struct {
+	UserID          string `json:"user_id" binding:"required"`
+	Reason          string `json:"reason" binding:"required,max=500"`
+	DurationSeconds *int   `json:"duration_seconds" binding:"omitempty,min=1,max=31536000"`
+}
+
+type MuteUserRequest struct {
+	UserID          string `json:"user_id" binding:"required"`
+	Reason          string `json:"reason" binding:"required,max=500"`
+	DurationSeconds int    `json:"duration_seconds" binding:"required,min=1,max=31536000"`
+}
+
+type CreateRoomRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=1,max=50"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type UpdateRoomRoleRequest struct {
+	Name        *string   `json:"name" binding:"omitempty,min=1,max=50"`
+	Permissions *[]string `json:"permissions"`
+}
+
+type AssignRoomRoleRequest struct {
+	RoleID *string `json:"role_id"`
+}
@@ -0,0 +1,25 @@
+package types
+
+type CreateChannelRequest struct {
+	Name        string  `json:"name" binding:"required,min=1,max=100"`
+	Description string  `json:"description"`
+	IsPrivate   bool    `json:"is_private"`
+	CategoryID  *string `json:"category_id"`
+	Position    int     `json:"position"`
+}
+
+// MoveChannelRequest moves a channel within its space; an empty category_id uncategorizes it
+type MoveChannelRequest struct {
+	CategoryID *string `json:"category_id"`
+	Position   *int    `json:"position"`
+}
+
+type CreateCategoryRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=100"`
+	Position int    `json:"position"`
+}
+
+type UpdateCategoryRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Position *int    `json:"position"`
+}
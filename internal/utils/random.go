@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// GenerateInviteCode returns a random URL-safe code suitable for sharing in links
+func GenerateInviteCode() (string, error) {
+	bytes := make([]byte, 9)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -0,0 +1,276 @@
+package websocket
+
+import (
+	"converse/internal/models"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Reaction actions reported in reaction_updated events
+const (
+	ReactionAdded   = "added"
+	ReactionRemoved = "removed"
+)
+
+// DeliverMessage pushes a newly stored message to its audience and echoes it back to the sender.
+// Thread replies only reach the thread's followers unless they were also sent to the room.
+func (h *Hub) DeliverMessage(message *models.Message, replyTo *models.ReplyPreview) {
+	senderID := ""
+	if message.SenderID != nil {
+		senderID = *message.SenderID
+	}
+
+	outgoingMsg := OutgoingMessage{
+		Type:             MessageTypeNewMessage,
+		MessageID:        message.MessageID,
+		RoomID:           message.RoomID,
+		ThreadID:         message.ThreadID,
+		SenderID:         senderID,
+		Content:          message.Content,
+		ContentType:      message.ContentType,
+		CreatedAt:        message.CreatedAt,
+		Metadata:         message.Metadata,
+		ReplyToMessageID: message.ReplyToMessageID,
+		ReplyTo:          replyTo,
+		ParentMessageID:  message.ParentMessageID,
+		AlsoSentToRoom:   message.AlsoSentToRoom,
+	}
+
+	// Send message back to sender first
+	if messageBytes, err := json.Marshal(outgoingMsg); err != nil {
+		log.Printf("Error marshaling message: %v", err)
+	} else {
+		h.SendToUser(senderID, messageBytes)
+	}
+
+	switch {
+	case message.ParentMessageID != nil && !message.AlsoSentToRoom:
+		if err := h.sendToFollowers(*message.ParentMessageID, *message.RoomID, outgoingMsg, senderID); err != nil {
+			log.Printf("Error sending thread reply to followers: %v", err)
+		}
+	case message.RoomID != nil:
+		if err := h.SendToRoom(*message.RoomID, outgoingMsg, senderID); err != nil {
+			log.Printf("Error sending message to room: %v", err)
+		}
+	case message.ThreadID != nil:
+		if err := h.SendToThread(*message.ThreadID, outgoingMsg, senderID); err != nil {
+			log.Printf("Error sending message to thread: %v", err)
+		}
+	}
+}
+
+// BroadcastThreadUpdate pushes a thread's new reply count and last reply time to its room
+func (h *Hub) BroadcastThreadUpdate(parent *models.Message) {
+	threadUpdate := OutgoingMessage{
+		Type:        MessageTypeThreadUpdated,
+		MessageID:   parent.MessageID,
+		RoomID:      parent.RoomID,
+		CreatedAt:   parent.CreatedAt,
+		ReplyCount:  parent.ReplyCount,
+		LastReplyAt: parent.LastReplyAt,
+	}
+	if err := h.SendToRoom(*parent.RoomID, threadUpdate, ""); err != nil {
+		log.Printf("Error sending thread update to room: %v", err)
+	}
+}
+
+// BroadcastMessageEvent pushes an event about an existing message, made by the actor, to everyone
+// who received the message
+func (h *Hub) BroadcastMessageEvent(eventType WebSocketMessageType, message *models.Message, actorID string) {
+	outgoingMsg := OutgoingMessage{
+		Type:            eventType,
+		MessageID:       message.MessageID,
+		RoomID:          message.RoomID,
+		ThreadID:        message.ThreadID,
+		Content:         message.Content,
+		ContentType:     message.ContentType,
+		CreatedAt:       message.CreatedAt,
+		UpdatedAt:       message.UpdatedAt,
+		DeletedAt:       message.DeletedAt,
+		PinnedAt:        message.PinnedAt,
+		PinnedBy:        message.PinnedBy,
+		Metadata:        message.Metadata,
+		ParentMessageID: message.ParentMessageID,
+		AlsoSentToRoom:  message.AlsoSentToRoom,
+	}
+	if message.SenderID != nil {
+		outgoingMsg.SenderID = *message.SenderID
+	}
+
+	h.sendToConversation(message, outgoingMsg, actorID)
+}
+
+// BroadcastReactionUpdate tells everyone who received the message that a reaction changed, along with the new count for that emoji
+func (h *Hub) BroadcastReactionUpdate(message *models.Message, userID, emoji, action string, count int64) {
+	metadata := models.Metadata{
+		"action": action,
+		"count":  count,
+	}
+
+	outgoingMsg := OutgoingMessage{
+		Type:            MessageTypeReactionUpdated,
+		MessageID:       message.MessageID,
+		RoomID:          message.RoomID,
+		ThreadID:        message.ThreadID,
+		CreatedAt:       time.Now(),
+		UserID:          userID,
+		Emoji:           emoji,
+		Metadata:        &metadata,
+		ParentMessageID: message.ParentMessageID,
+		AlsoSentToRoom:  message.AlsoSentToRoom,
+	}
+
+	h.sendToConversation(message, outgoingMsg, userID)
+}
+
+// BroadcastReadReceipt tells everyone else in the conversation how far the user has read
+func (h *Hub) BroadcastReadReceipt(userID string, message *models.Message) {
+	receipt := OutgoingMessage{
+		Type:      MessageTypeReadReceipt,
+		MessageID: message.MessageID,
+		RoomID:    message.RoomID,
+		ThreadID:  message.ThreadID,
+		UserID:    userID,
+		CreatedAt: message.CreatedAt,
+	}
+
+	if message.RoomID != nil {
+		if err := h.SendToRoom(*message.RoomID, receipt, userID); err != nil {
+			log.Printf("Error sending read receipt to room: %v", err)
+		}
+	} else if message.ThreadID != nil {
+		if err := h.SendToThread(*message.ThreadID, receipt, userID); err != nil {
+			log.Printf("Error sending read receipt to thread: %v", err)
+		}
+	}
+}
+
+// SendMention sends each recipient a mention event for the message
+func (h *Hub) SendMention(message *models.Message, recipients []string) {
+	mention := OutgoingMessage{
+		Type:            MessageTypeMention,
+		MessageID:       message.MessageID,
+		RoomID:          message.RoomID,
+		SenderID:        *message.SenderID,
+		Content:         message.Content,
+		ContentType:     message.ContentType,
+		CreatedAt:       message.CreatedAt,
+		Metadata:        message.Metadata,
+		ParentMessageID: message.ParentMessageID,
+	}
+	mentionBytes, err := json.Marshal(mention)
+	if err != nil {
+		log.Printf("Error marshaling mention: %v", err)
+		return
+	}
+
+	for _, userID := range recipients {
+		h.SendToUser(userID, mentionBytes)
+	}
+}
+
+// SendScheduledFailure tells the sender that one of their scheduled messages was not sent
+func (h *Hub) SendScheduledFailure(scheduled *models.ScheduledMessage, reason string) {
+	failure := OutgoingMessage{
+		Type:        MessageTypeScheduledMessageFailed,
+		MessageID:   scheduled.ScheduledMessageID,
+		RoomID:      scheduled.RoomID,
+		ThreadID:    scheduled.ThreadID,
+		SenderID:    scheduled.SenderID,
+		Content:     scheduled.Content,
+		ContentType: "text",
+		CreatedAt:   time.Now(),
+		Error:       reason,
+	}
+
+	failureBytes, err := json.Marshal(failure)
+	if err != nil {
+		log.Printf("Error marshaling scheduled message failure: %v", err)
+		return
+	}
+	h.SendToUser(scheduled.SenderID, failureBytes)
+}
+
+// BroadcastMembershipChange pushes the user_joined / user_left event for a stored membership
+// notification to the room. The affected user is always notified, even when they are no longer a member.
+func (h *Hub) BroadcastMembershipChange(notification *models.Message, eventType WebSocketMessageType, userID string) {
+	outgoingMsg := OutgoingMessage{
+		Type:        eventType,
+		MessageID:   notification.MessageID,
+		RoomID:      notification.RoomID,
+		Content:     notification.Content,
+		ContentType: notification.ContentType,
+		CreatedAt:   notification.CreatedAt,
+		UserID:      userID,
+		Metadata:    notification.Metadata,
+	}
+
+	if err := h.SendToRoom(*notification.RoomID, outgoingMsg, userID); err != nil {
+		log.Printf("Error sending membership event to room: %v", err)
+	}
+
+	messageBytes, err := json.Marshal(outgoingMsg)
+	if err != nil {
+		log.Printf("Error marshaling membership event: %v", err)
+		return
+	}
+	h.SendToUser(userID, messageBytes)
+}
+
+// sendToConversation delivers an event about a message to everyone who received it, including the
+// actor. Events about thread replies that stayed in the thread only go to the thread's followers.
+func (h *Hub) sendToConversation(message *models.Message, outgoingMsg OutgoingMessage, actorID string) {
+	switch {
+	case message.ParentMessageID != nil && !message.AlsoSentToRoom:
+		if err := h.sendToFollowers(*message.ParentMessageID, *message.RoomID, outgoingMsg, actorID); err != nil {
+			log.Printf("Error sending %s event to thread followers: %v", outgoingMsg.Type, err)
+			return
+		}
+		// The actor may not follow the thread, as when a moderator deletes a reply
+		if messageBytes, err := json.Marshal(outgoingMsg); err != nil {
+			log.Printf("Error marshaling %s event: %v", outgoingMsg.Type, err)
+		} else {
+			h.SendToUser(actorID, messageBytes)
+		}
+	case message.RoomID != nil:
+		if err := h.SendToRoom(*message.RoomID, outgoingMsg, ""); err != nil {
+			log.Printf("Error sending %s event to room: %v", outgoingMsg.Type, err)
+		}
+	case message.ThreadID != nil:
+		if err := h.SendToThread(*message.ThreadID, outgoingMsg, ""); err != nil {
+			log.Printf("Error sending %s event to thread: %v", outgoingMsg.Type, err)
+		}
+	}
+}
+
+// sendToFollowers sends a message to the thread's followers that are still in the room's audience
+func (h *Hub) sendToFollowers(parentID, roomID string, message OutgoingMessage, excludeUserID string) error {
+	followers, err := h.followerRepo.GetFollowerIDs(parentID)
+	if err != nil {
+		return err
+	}
+
+	audience, err := h.getRoomMembers(roomID)
+	if err != nil {
+		return err
+	}
+
+	inRoom := make(map[string]bool, len(audience))
+	for _, userID := range audience {
+		inRoom[userID] = true
+	}
+
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range followers {
+		if userID != excludeUserID && inRoom[userID] {
+			h.SendToUser(userID, messageBytes)
+		}
+	}
+
+	return nil
+}
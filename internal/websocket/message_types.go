@@ -1,12 +1,31 @@
 package websocket
 
-import "time"
+import (
+	"converse/internal/models"
+	"time"
+)
 
 // WebSocketMessageType represents the type of WebSocket message
 type WebSocketMessageType string
 
 const (
 	MessageTypeNewMessage    WebSocketMessageType = "new_message"
+	MessageTypeEditMessage   WebSocketMessageType = "edit_message"
+	MessageTypeMessageEdited WebSocketMessageType = "message_edited"
+	MessageTypeDeleteMessage WebSocketMessageType = "delete_message"
+	MessageTypeMessageDeleted WebSocketMessageType = "message_deleted"
+	MessageTypeAddReaction    WebSocketMessageType = "add_reaction"
+	MessageTypeRemoveReaction WebSocketMessageType = "remove_reaction"
+	MessageTypeReactionUpdated WebSocketMessageType = "reaction_updated"
+	MessageTypeThreadUpdated  WebSocketMessageType = "thread_updated"
+	MessageTypePinMessage     WebSocketMessageType = "pin_message"
+	MessageTypeUnpinMessage   WebSocketMessageType = "unpin_message"
+	MessageTypeMessagePinned  WebSocketMessageType = "message_pinned"
+	MessageTypeMessageUnpinned WebSocketMessageType = "message_unpinned"
+	MessageTypeMention        WebSocketMessageType = "mention"
+	MessageTypeMarkRead       WebSocketMessageType = "mark_read"
+	MessageTypeReadReceipt    WebSocketMessageType = "read_receipt"
+	MessageTypeScheduledMessageFailed WebSocketMessageType = "scheduled_message_failed"
 	MessageTypeUserJoined    WebSocketMessageType = "user_joined"
 	MessageTypeUserLeft      WebSocketMessageType = "user_left"
 	MessageTypeTyping        WebSocketMessageType = "typing"
@@ -16,6 +35,17 @@ const (
 	MessageTypePong          WebSocketMessageType = "pong"
 )
 
+// Reasons attached to user_joined / user_left events
+const (
+	MembershipReasonJoin       = "join"
+	MembershipReasonAdded      = "added"
+	MembershipReasonInvite     = "invite"
+	MembershipReasonInviteLink = "invite_link"
+	MembershipReasonLeave      = "leave"
+	MembershipReasonKick       = "kick"
+	MembershipReasonBan        = "ban"
+)
+
 // IncomingMessage represents a message received from a client
 type IncomingMessage struct {
 	Type      WebSocketMessageType `json:"type"`
@@ -23,6 +53,11 @@ type IncomingMessage struct {
 	ThreadID  *string             `json:"thread_id,omitempty"`
 	Content   string              `json:"content"`
 	ContentType string            `json:"content_type,omitempty"`
+	MessageID   string            `json:"message_id,omitempty"`
+	Emoji       string            `json:"emoji,omitempty"`
+	ReplyToMessageID *string      `json:"reply_to_message_id,omitempty"`
+	ParentMessageID  *string      `json:"parent_message_id,omitempty"`
+	AlsoSendToRoom   bool         `json:"also_send_to_room,omitempty"`
 }
 
 // OutgoingMessage represents a message sent to clients
@@ -36,4 +71,18 @@ type OutgoingMessage struct {
 	ContentType string              `json:"content_type"`
 	CreatedAt   time.Time           `json:"created_at"`
 	Error       string              `json:"error,omitempty"`
+	UserID      string              `json:"user_id,omitempty"`
+	Metadata    *models.Metadata    `json:"metadata,omitempty"`
+	RetryAfter  int                 `json:"retry_after,omitempty"`
+	UpdatedAt   *time.Time          `json:"updated_at,omitempty"`
+	DeletedAt   *time.Time          `json:"deleted_at,omitempty"`
+	Emoji       string              `json:"emoji,omitempty"`
+	ReplyToMessageID *string        `json:"reply_to_message_id,omitempty"`
+	ReplyTo     *models.ReplyPreview `json:"reply_to,omitempty"`
+	ParentMessageID *string         `json:"parent_message_id,omitempty"`
+	AlsoSentToRoom  bool            `json:"also_sent_to_room,omitempty"`
+	ReplyCount  int                 `json:"reply_count,omitempty"`
+	LastReplyAt *time.Time          `json:"last_reply_at,omitempty"`
+	PinnedAt    *time.Time          `json:"pinned_at,omitempty"`
+	PinnedBy    *string             `json:"pinned_by,omitempty"`
 }
\ No newline at end of file
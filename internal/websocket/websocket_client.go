@@ -50,14 +50,16 @@ func (c *Client) readPump() {
         var incomingMsg IncomingMessage
         if err := json.Unmarshal(message, &incomingMsg); err != nil {
             log.Printf("Error parsing message from %s: %v", c.UserID, err)
-            c.hub.sendErrorToClient(c, "Invalid message format")
+            c.hub.sendErrorToClient(c, "Invalid message format", 0)
             continue
         }
 
         log.Printf("Received message from %s: type=%s, content=%s", c.UserID, incomingMsg.Type, incomingMsg.Content)        // Route the message based on type
         switch incomingMsg.Type {
-        case MessageTypeNewMessage:
-            c.hub.ProcessIncomingMessage(c, incomingMsg)
+        case MessageTypeNewMessage, MessageTypeEditMessage, MessageTypeDeleteMessage,
+            MessageTypeAddReaction, MessageTypeRemoveReaction,
+            MessageTypePinMessage, MessageTypeUnpinMessage, MessageTypeMarkRead:
+            c.hub.handleAction(c, incomingMsg)
         case MessageTypeTyping:
             c.handleTypingIndicator(incomingMsg, true)
         case MessageTypeStopTyping:
@@ -66,7 +68,7 @@ func (c *Client) readPump() {
             c.handlePing()
         default:
             log.Printf("Unknown message type from %s: %s", c.UserID, incomingMsg.Type)
-            c.hub.sendErrorToClient(c, "Unknown message type")
+            c.hub.sendErrorToClient(c, "Unknown message type", 0)
         }
 	}
 }
@@ -3,7 +3,9 @@ package websocket
 import (
 	"converse/internal/models"
 	"converse/internal/repositories"
+	apperrors "converse/pkg/errors"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"sync"
@@ -45,8 +47,20 @@ type Hub struct {
     userClients map[string]*Client
     mutex sync.RWMutex
 
+    // Carries out the message actions clients send over the socket
+    actions ActionHandler
+
     // Repository dependencies for routing
-    messageRepo *repositories.MessageRepository
+    messageRepo  *repositories.MessageRepository
+    roomRepo     *repositories.RoomRepository
+    followerRepo *repositories.ThreadFollowerRepository
+}
+
+// ActionHandler carries out the message actions clients request over the socket, such as
+// sending, editing or reacting to messages. Errors meant for the client are *SendRejection
+// or *errors.AppError.
+type ActionHandler interface {
+    HandleAction(userID string, action IncomingMessage) error
 }
 
 func NewHub() *Hub {
@@ -56,10 +70,17 @@ func NewHub() *Hub {
         register:    make(chan *Client),
         unregister:  make(chan *Client),
         userClients: make(map[string]*Client),
-        messageRepo: repositories.NewMessageRepository(),
+        messageRepo:  repositories.NewMessageRepository(),
+        roomRepo:     repositories.NewRoomRepository(),
+        followerRepo: repositories.NewThreadFollowerRepository(),
     }
 }
 
+// SetActionHandler registers what carries out client message actions; call it before Run
+func (h *Hub) SetActionHandler(actions ActionHandler) {
+    h.actions = actions
+}
+
 func (h *Hub) Run() {
     for {
         select {
@@ -113,6 +134,14 @@ func (h *Hub) SendToUser(userID string, message []byte) {
     }
 }
 
+// IsOnline reports whether the user has a connected client
+func (h *Hub) IsOnline(userID string) bool {
+    h.mutex.RLock()
+    defer h.mutex.RUnlock()
+    _, online := h.userClients[userID]
+    return online
+}
+
 // SendToRoom sends a message to all members of a specific room
 func (h *Hub) SendToRoom(roomID string, message OutgoingMessage, excludeUserID string) error {
     // Get all room members from database
@@ -133,12 +162,12 @@ func (h *Hub) SendToRoom(roomID string, message OutgoingMessage, excludeUserID s
     h.mutex.RLock()
     defer h.mutex.RUnlock()
     
-    for _, member := range roomMembers {
-        if member.UserID != excludeUserID {
-            if client, exists := h.userClients[member.UserID]; exists {
+    for _, userID := range roomMembers {
+        if userID != excludeUserID {
+            if client, exists := h.userClients[userID]; exists {
                 select {
                 case client.send <- messageBytes:
-                    log.Printf("Message sent to user %s in room %s", member.UserID, roomID)
+                    log.Printf("Message sent to user %s in room %s", userID, roomID)
                 default:
                     // Client's send channel is full, clean up
                     h.cleanupClient(client)
@@ -187,79 +216,65 @@ func (h *Hub) SendToThread(threadID string, message OutgoingMessage, excludeUser
     return nil
 }
 
-// ProcessIncomingMessage handles routing of incoming messages from clients
-func (h *Hub) ProcessIncomingMessage(client *Client, incomingMsg IncomingMessage) {
-    // Validate message
-    if incomingMsg.Content == "" {
-        h.sendErrorToClient(client, "Message content cannot be empty")
+// handleAction passes a message action from a client to the action handler, reporting any failure back to the client
+func (h *Hub) handleAction(client *Client, incomingMsg IncomingMessage) {
+    if h.actions == nil {
+        h.sendErrorToClient(client, "Message actions are not available", 0)
         return
     }
 
-    // Create message model for database storage
-    message := &models.Message{
-        RoomID:      incomingMsg.RoomID,
-        ThreadID:    incomingMsg.ThreadID,
-        SenderID:    &client.UserID,
-        Content:     incomingMsg.Content,
-        ContentType: incomingMsg.ContentType,
-    }
-
-    if message.ContentType == "" {
-        message.ContentType = "text"
+    if err := h.actions.HandleAction(client.UserID, incomingMsg); err != nil {
+        h.handleActionError(client, incomingMsg.Type, err)
     }
+}
 
-    // Store message in database first
-    if err := h.messageRepo.StoreMessage(message); err != nil {
-        log.Printf("Error storing message: %v", err)
-        h.sendErrorToClient(client, "Failed to store message")
+// handleActionError reports a failed action to the client, hiding unexpected errors behind a generic message
+func (h *Hub) handleActionError(client *Client, actionType WebSocketMessageType, err error) {
+    var rejection *SendRejection
+    if errors.As(err, &rejection) {
+        h.sendErrorToClient(client, rejection.Reason, rejection.RetryAfter)
         return
     }
 
-    // Create outgoing message for broadcasting
-    outgoingMsg := OutgoingMessage{
-        Type:        MessageTypeNewMessage,
-        MessageID:   message.MessageID,
-        RoomID:      message.RoomID,
-        ThreadID:    message.ThreadID,
-        SenderID:    client.UserID,
-        Content:     message.Content,
-        ContentType: message.ContentType,
-        CreatedAt:   message.CreatedAt,
-    }    // Convert message to JSON for sending back to sender
-    messageBytes, err := json.Marshal(outgoingMsg)
-    if err != nil {
-        log.Printf("Error marshaling message: %v", err)
-        h.sendErrorToClient(client, "Failed to process message")
+    var appErr *apperrors.AppError
+    if errors.As(err, &appErr) {
+        h.sendErrorToClient(client, appErr.Message, 0)
         return
     }
 
-    // Send message back to sender first
-    select {
-    case client.send <- messageBytes:
-        log.Printf("Message echoed back to sender %s", client.UserID)
-    default:
-        log.Printf("Failed to echo message back to sender %s", client.UserID)
+    log.Printf("Error handling %s from %s: %v", actionType, client.UserID, err)
+    fallback, ok := actionFailures[actionType]
+    if !ok {
+        fallback = "Failed to process message"
     }
+    h.sendErrorToClient(client, fallback, 0)
+}
 
-    // Route the message based on type
-    if message.RoomID != nil {
-        // It's a room message
-        if err := h.SendToRoom(*message.RoomID, outgoingMsg, client.UserID); err != nil {
-            log.Printf("Error sending message to room: %v", err)
-        }
-    } else if message.ThreadID != nil {
-        // It's a DM thread message
-        if err := h.SendToThread(*message.ThreadID, outgoingMsg, client.UserID); err != nil {
-            log.Printf("Error sending message to thread: %v", err)
-        }
-    }
+// actionFailures are the generic errors shown to clients when an action fails unexpectedly
+var actionFailures = map[WebSocketMessageType]string{
+    MessageTypeEditMessage:    "Failed to edit message",
+    MessageTypeDeleteMessage:  "Failed to delete message",
+    MessageTypeAddReaction:    "Failed to add reaction",
+    MessageTypeRemoveReaction: "Failed to remove reaction",
+    MessageTypePinMessage:     "Failed to pin message",
+    MessageTypeUnpinMessage:   "Failed to unpin message",
+    MessageTypeMarkRead:       "Failed to mark message as read",
+}
+
+// SendRejection explains to the client why their message was not accepted.
+// RetryAfter is set, in seconds, when the client may try again later.
+type SendRejection struct {
+    Reason     string
+    RetryAfter int
+}
+
+func (e *SendRejection) Error() string {
+    return e.Reason
 }
 
 // Helper methods for database queries
-func (h *Hub) getRoomMembers(roomID string) ([]*models.RoomMember, error) {
-    var members []*models.RoomMember
-    err := h.messageRepo.DB().Where("room_id = ?", roomID).Find(&members).Error
-    return members, err
+func (h *Hub) getRoomMembers(roomID string) ([]string, error) {
+    return h.roomRepo.GetAudienceUserIDs(roomID)
 }
 
 func (h *Hub) getThreadParticipants(threadID string) ([]string, error) {
@@ -271,10 +286,13 @@ func (h *Hub) getThreadParticipants(threadID string) ([]string, error) {
     return []string{thread.User1ID, thread.User2ID}, nil
 }
 
-func (h *Hub) sendErrorToClient(client *Client, errorMsg string) {
+// sendErrorToClient reports an error to the client. retryAfter, in seconds, tells the client
+// when it may try again and is left out of the message when zero.
+func (h *Hub) sendErrorToClient(client *Client, errorMsg string, retryAfter int) {
     errorMessage := OutgoingMessage{
-        Type:  MessageTypeError,
-        Error: errorMsg,
+        Type:       MessageTypeError,
+        Error:      errorMsg,
+        RetryAfter: retryAfter,
     }
     
     messageBytes, _ := json.Marshal(errorMessage)
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SchemaMigration records a data migration that has been applied, so it runs only once
+type SchemaMigration struct {
+	Name      string    `gorm:"column:name;type:varchar(191);primaryKey"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// dataMigration is a one-time change to existing rows. Names must never change once released.
+type dataMigration struct {
+	name string
+	run  func(tx *gorm.DB) error
+}
+
+var dataMigrations = []dataMigration{
+	{
+		// Backfill last_message_at for conversations from before it was maintained on send
+		name: "backfill_rooms_last_message_at",
+		run: func(tx *gorm.DB) error {
+			return tx.Exec(`UPDATE rooms r SET last_message_at = (
+				SELECT MAX(m.created_at) FROM messages m
+				WHERE m.room_id = r.room_id AND (m.parent_message_id IS NULL OR m.also_sent_to_room = true)
+			) WHERE r.last_message_at IS NULL`).Error
+		},
+	},
+	{
+		name: "backfill_direct_message_threads_last_message_at",
+		run: func(tx *gorm.DB) error {
+			return tx.Exec(`UPDATE direct_message_threads t SET last_message_at = (
+				SELECT MAX(m.created_at) FROM messages m WHERE m.thread_id = t.thread_id
+			) WHERE t.last_message_at IS NULL`).Error
+		},
+	},
+}
+
+// runDataMigrations applies each data migration that is not yet recorded in schema_migrations.
+// The record is inserted first in the migration's transaction, so a second instance starting at
+// the same time waits on it and then skips the migration.
+func runDataMigrations(database *gorm.DB) error {
+	for _, migration := range dataMigrations {
+		err := database.Transaction(func(tx *gorm.DB) error {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&SchemaMigration{Name: migration.name, AppliedAt: time.Now()})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+
+			if err := migration.run(tx); err != nil {
+				return err
+			}
+			log.Printf("Applied data migration %s", migration.name)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
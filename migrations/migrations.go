@@ -23,13 +23,30 @@ func RunMigrations() error {
         &friends.Friendship{},
         &models.DirectMessageThread{},
         &models.Message{},
-        // &models.Room{},
-        // &models.RoomMember{},
+        &models.MessageEdit{},
+        &models.MessageReaction{},
+        &models.ThreadFollower{},
+        &models.MessageMention{},
+        &models.ScheduledMessage{},
+        &models.Room{},
+        &models.SpaceCategory{},
+        &models.RoomRole{},
+        &models.RoomMember{},
+        &models.RoomReadMarker{},
+        &models.RoomInvitation{},
+        &models.RoomInviteLink{},
+        &models.RoomBan{},
+        &models.RoomMute{},
+        &SchemaMigration{},
     )
     if err != nil {
         return err
     }
 
+    if err := runDataMigrations(database); err != nil {
+        return err
+    }
+
     log.Println("Migrations completed successfully")
     return nil
 }
\ No newline at end of file
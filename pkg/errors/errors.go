@@ -48,3 +48,10 @@ func NewForbiddenError(message string) *AppError {
 		Message: message,
 	}
 }
+
+func NewNotFoundError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusNotFound,
+		Message: message,
+	}
+}